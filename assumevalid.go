@@ -0,0 +1,63 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+// ErrAssumeValidBelowCheckpoint describes an error where the assumed valid
+// block of a network is a checkpoint other than its latest one.
+var ErrAssumeValidBelowCheckpoint = errors.New("assume valid block is below " +
+	"the latest checkpoint")
+
+// HasEnoughWork returns whether a chain with the passed total work has at least
+// the MinimumChainWork of the network.  Every chain has enough work when the
+// network does not define a minimum.
+func (p *Params) HasEnoughWork(totalWork *big.Int) bool {
+	if p.MinimumChainWork == nil {
+		return true
+	}
+	return totalWork.Cmp(p.MinimumChainWork) >= 0
+}
+
+// IsAssumedValid returns whether the block with the passed hash and height is
+// the AssumeValid block of the network or one of its ancestors, in which case
+// the verification of its scripts may be skipped.
+//
+// The passed function must return the hash of the ancestor at the passed
+// height of the block with the passed hash, or nil when the block is not known
+// or the height is above it.  It is only called with the AssumeValid hash.
+func (p *Params) IsAssumedValid(hash *chainhash.Hash, height int32,
+	ancestorFn func(hash *chainhash.Hash, height int32) *chainhash.Hash) bool {
+
+	if p.AssumeValid == nil {
+		return false
+	}
+
+	ancestor := ancestorFn(p.AssumeValid, height)
+	return ancestor != nil && ancestor.IsEqual(hash)
+}
+
+// validateAssumeValid ensures the AssumeValid block of the network is not below
+// its latest checkpoint.  Since the parameters do not record the height of
+// arbitrary blocks, this can only be determined from the checkpoints
+// themselves: the AssumeValid hash must not be the hash of a checkpoint other
+// than the latest one.
+func (p *Params) validateAssumeValid() error {
+	if p.AssumeValid == nil || len(p.Checkpoints) == 0 {
+		return nil
+	}
+
+	for _, checkpoint := range p.Checkpoints[:len(p.Checkpoints)-1] {
+		if checkpoint.Hash.IsEqual(p.AssumeValid) {
+			return ErrAssumeValidBelowCheckpoint
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+// TestHasEnoughWork ensures chains are compared against the minimum chain
+// work.
+func TestHasEnoughWork(t *testing.T) {
+	params := Params{MinimumChainWork: big.NewInt(1000)}
+
+	tests := []struct {
+		params *Params
+		work   int64
+		want   bool
+	}{
+		{&params, 999, false},
+		{&params, 1000, true},
+		{&params, 1001, true},
+		{&Params{}, 0, true},
+	}
+
+	for _, test := range tests {
+		got := test.params.HasEnoughWork(big.NewInt(test.work))
+		if got != test.want {
+			t.Errorf("HasEnoughWork(%d) = %v, want %v", test.work, got,
+				test.want)
+		}
+	}
+}
+
+// TestIsAssumedValid ensures only the assumed valid block and its ancestors
+// are assumed valid.
+func TestIsAssumedValid(t *testing.T) {
+	// The chain is made of blocks whose hash is derived from their height,
+	// with the assumed valid block at height 100.
+	hashAt := func(height int32) *chainhash.Hash {
+		return newHashFromStr(big.NewInt(int64(height) + 1).Text(16))
+	}
+	assumeValid := hashAt(100)
+	ancestorFn := func(hash *chainhash.Hash, height int32) *chainhash.Hash {
+		if !hash.IsEqual(assumeValid) || height > 100 {
+			return nil
+		}
+		return hashAt(height)
+	}
+	params := Params{AssumeValid: assumeValid}
+
+	tests := []struct {
+		name   string
+		params *Params
+		hash   *chainhash.Hash
+		height int32
+		want   bool
+	}{
+		{"ancestor", &params, hashAt(50), 50, true},
+		{"assumed valid block", &params, hashAt(100), 100, true},
+		{"above assumed valid block", &params, hashAt(101), 101, false},
+		{"side chain block", &params, hashAt(49), 50, false},
+		{"disabled", &Params{}, hashAt(50), 50, false},
+	}
+
+	for _, test := range tests {
+		got := test.params.IsAssumedValid(test.hash, test.height,
+			ancestorFn)
+		if got != test.want {
+			t.Errorf("%s: IsAssumedValid = %v, want %v", test.name,
+				got, test.want)
+		}
+	}
+}
+
+// TestValidateAssumeValid ensures the assumed valid block may not be a
+// checkpoint below the latest checkpoint.
+func TestValidateAssumeValid(t *testing.T) {
+	tests := []struct {
+		name        string
+		checkpoints []Checkpoint
+		hash        *chainhash.Hash
+		err         error
+	}{
+		{"after latest checkpoint", checkpointTestParams().Checkpoints,
+			newHashFromStr("ff"), nil},
+		{"latest checkpoint", checkpointTestParams().Checkpoints,
+			newHashFromStr("1e"), nil},
+		{"earlier checkpoint", checkpointTestParams().Checkpoints,
+			newHashFromStr("14"), ErrAssumeValidBelowCheckpoint},
+		{"first checkpoint", checkpointTestParams().Checkpoints,
+			newHashFromStr("0a"), ErrAssumeValidBelowCheckpoint},
+		{"no checkpoints", nil, newHashFromStr("0a"), nil},
+		{"disabled", checkpointTestParams().Checkpoints, nil, nil},
+	}
+
+	for _, test := range tests {
+		params := Params{
+			Checkpoints: test.checkpoints,
+			AssumeValid: test.hash,
+		}
+		if err := params.validateAssumeValid(); err != test.err {
+			t.Errorf("%s: validateAssumeValid = %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
@@ -5,7 +5,11 @@
 
 package chainhash
 
-import "crypto/sha256"
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/ripemd160"
+)
 
 // HashB calculates hash(b) and returns the resulting bytes.
 func HashB(b []byte) []byte {
@@ -31,3 +35,13 @@ func DoubleHashH(b []byte) Hash {
 	first := sha256.Sum256(b)
 	return Hash(sha256.Sum256(first[:]))
 }
+
+// Hash160 calculates ripemd160(sha256(b)) and returns the resulting bytes.  It
+// matches ltcutil.Hash160, which can not be used by the packages ltcutil
+// depends on, such as chaincfg.
+func Hash160(b []byte) []byte {
+	sha := sha256.Sum256(b)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	return hasher.Sum(nil)
+}
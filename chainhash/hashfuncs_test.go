@@ -5,6 +5,7 @@
 package chainhash
 
 import (
+	"encoding/hex"
 	"fmt"
 	"testing"
 )
@@ -134,3 +135,31 @@ func TestDoubleHashFuncs(t *testing.T) {
 		}
 	}
 }
+
+// TestHash160 ensures the hash function which performs ripemd160(sha256(b))
+// works as expected.
+func TestHash160(t *testing.T) {
+	tests := []struct {
+		out string
+		in  string
+	}{
+		{"b472a266d0bd89c13706a4132ccfb16f7c3b9fcb", ""},
+
+		// The public key of the Bitcoin genesis block coinbase, which
+		// pays to 1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa.
+		{"62e907b15cbf27d5425399ebf6f0fb50ebb88f18", "04678afdb0fe5548" +
+			"271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc" +
+			"3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5f"},
+	}
+
+	for _, test := range tests {
+		in, err := hex.DecodeString(test.in)
+		if err != nil {
+			t.Fatalf("invalid test input %q: %v", test.in, err)
+		}
+		h := fmt.Sprintf("%x", Hash160(in))
+		if h != test.out {
+			t.Errorf("Hash160(%s) = %s, want %s", test.in, h, test.out)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chainhash
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"math/bits"
+	"sync"
+)
+
+// These constants define the scrypt parameters used for the proof of work
+// hash of a block header.  They match those used by Litecoin and its
+// derivatives.
+const (
+	// scryptN is the CPU/memory cost parameter.
+	scryptN = 1024
+
+	// scryptR is the block size parameter.  The proof of work hash uses
+	// r = 1, so each block is 128 bytes (32 words).
+	scryptR = 1
+
+	// scryptBlockWords is the number of 32-bit words in a single scrypt
+	// block.
+	scryptBlockWords = 32 * scryptR
+
+	// scryptBlockBytes is the number of bytes in a single scrypt block.
+	scryptBlockBytes = 4 * scryptBlockWords
+)
+
+// scryptScratch houses the working memory needed to compute a single scrypt
+// proof of work hash.  The vector V alone is 128 KiB, so scratch spaces are
+// recycled through scryptPool rather than being allocated for every hash.
+type scryptScratch struct {
+	v     [scryptN * scryptBlockWords]uint32
+	x     [scryptBlockWords]uint32
+	b     [scryptBlockBytes]byte
+	key   [sha256.BlockSize]byte
+	ipad  [sha256.BlockSize]byte
+	opad  [sha256.BlockSize]byte
+	sum   [sha256.Size]byte
+	ctr   [4]byte
+	inner hash.Hash
+	outer hash.Hash
+}
+
+// scryptPool is a pool of scratch spaces shared by all callers of
+// ScryptPoWHash.
+var scryptPool = sync.Pool{
+	New: func() interface{} {
+		return &scryptScratch{
+			inner: sha256.New(),
+			outer: sha256.New(),
+		}
+	},
+}
+
+// setKey prepares the HMAC-SHA256 pads for the provided key.
+func (s *scryptScratch) setKey(key []byte) {
+	s.key = [sha256.BlockSize]byte{}
+	if len(key) > sha256.BlockSize {
+		s.inner.Reset()
+		s.inner.Write(key)
+		s.inner.Sum(s.key[:0])
+	} else {
+		copy(s.key[:], key)
+	}
+	for i, k := range s.key {
+		s.ipad[i] = k ^ 0x36
+		s.opad[i] = k ^ 0x5c
+	}
+}
+
+// hmacBlock computes a single block of PBKDF2-HMAC-SHA256 with an iteration
+// count of one, HMAC(key, salt || INT(index)), using the pads prepared by
+// setKey and writes the result to dst.
+func (s *scryptScratch) hmacBlock(dst, salt []byte, index uint32) {
+	binary.BigEndian.PutUint32(s.ctr[:], index)
+
+	s.inner.Reset()
+	s.inner.Write(s.ipad[:])
+	s.inner.Write(salt)
+	s.inner.Write(s.ctr[:])
+	s.inner.Sum(s.sum[:0])
+
+	s.outer.Reset()
+	s.outer.Write(s.opad[:])
+	s.outer.Write(s.sum[:])
+	s.outer.Sum(dst[:0])
+}
+
+// salsa208 applies the Salsa20/8 core to the 16 words in b after xoring them
+// with the 16 words in x.  The result is stored in b.
+func salsa208(b, x []uint32) {
+	var w [16]uint32
+	for i := range w {
+		b[i] ^= x[i]
+		w[i] = b[i]
+	}
+
+	for i := 0; i < 8; i += 2 {
+		w[4] ^= bits.RotateLeft32(w[0]+w[12], 7)
+		w[8] ^= bits.RotateLeft32(w[4]+w[0], 9)
+		w[12] ^= bits.RotateLeft32(w[8]+w[4], 13)
+		w[0] ^= bits.RotateLeft32(w[12]+w[8], 18)
+
+		w[9] ^= bits.RotateLeft32(w[5]+w[1], 7)
+		w[13] ^= bits.RotateLeft32(w[9]+w[5], 9)
+		w[1] ^= bits.RotateLeft32(w[13]+w[9], 13)
+		w[5] ^= bits.RotateLeft32(w[1]+w[13], 18)
+
+		w[14] ^= bits.RotateLeft32(w[10]+w[6], 7)
+		w[2] ^= bits.RotateLeft32(w[14]+w[10], 9)
+		w[6] ^= bits.RotateLeft32(w[2]+w[14], 13)
+		w[10] ^= bits.RotateLeft32(w[6]+w[2], 18)
+
+		w[3] ^= bits.RotateLeft32(w[15]+w[11], 7)
+		w[7] ^= bits.RotateLeft32(w[3]+w[15], 9)
+		w[11] ^= bits.RotateLeft32(w[7]+w[3], 13)
+		w[15] ^= bits.RotateLeft32(w[11]+w[7], 18)
+
+		w[1] ^= bits.RotateLeft32(w[0]+w[3], 7)
+		w[2] ^= bits.RotateLeft32(w[1]+w[0], 9)
+		w[3] ^= bits.RotateLeft32(w[2]+w[1], 13)
+		w[0] ^= bits.RotateLeft32(w[3]+w[2], 18)
+
+		w[6] ^= bits.RotateLeft32(w[5]+w[4], 7)
+		w[7] ^= bits.RotateLeft32(w[6]+w[5], 9)
+		w[4] ^= bits.RotateLeft32(w[7]+w[6], 13)
+		w[5] ^= bits.RotateLeft32(w[4]+w[7], 18)
+
+		w[11] ^= bits.RotateLeft32(w[10]+w[9], 7)
+		w[8] ^= bits.RotateLeft32(w[11]+w[10], 9)
+		w[9] ^= bits.RotateLeft32(w[8]+w[11], 13)
+		w[10] ^= bits.RotateLeft32(w[9]+w[8], 18)
+
+		w[12] ^= bits.RotateLeft32(w[15]+w[14], 7)
+		w[13] ^= bits.RotateLeft32(w[12]+w[15], 9)
+		w[14] ^= bits.RotateLeft32(w[13]+w[12], 13)
+		w[15] ^= bits.RotateLeft32(w[14]+w[13], 18)
+	}
+
+	for i := range w {
+		b[i] += w[i]
+	}
+}
+
+// romix performs the scrypt ROMix function with N = scryptN and r = 1 on the
+// words in s.x using s.v as the scratchpad.
+func (s *scryptScratch) romix() {
+	x := s.x[:]
+	for i := 0; i < scryptN; i++ {
+		copy(s.v[i*scryptBlockWords:], x)
+		salsa208(x[:16], x[16:])
+		salsa208(x[16:], x[:16])
+	}
+	for i := 0; i < scryptN; i++ {
+		j := int(x[16]&(scryptN-1)) * scryptBlockWords
+		v := s.v[j : j+scryptBlockWords]
+		for k := range x {
+			x[k] ^= v[k]
+		}
+		salsa208(x[:16], x[16:])
+		salsa208(x[16:], x[:16])
+	}
+}
+
+// ScryptPoWHash calculates the scrypt proof of work hash of the passed
+// serialized block header using the parameters N = 1024, r = 1, p = 1 and a
+// 32-byte output, with the header acting as both the password and the salt.
+//
+// The 128 KiB scratchpad required by scrypt is reused between calls, so it is
+// safe and cheap to call this concurrently from many goroutines.
+func ScryptPoWHash(header []byte) Hash {
+	s := scryptPool.Get().(*scryptScratch)
+	defer scryptPool.Put(s)
+
+	// B = PBKDF2-HMAC-SHA256(header, header, 1, 128).
+	s.setKey(header)
+	for i := 0; i < scryptBlockBytes/sha256.Size; i++ {
+		s.hmacBlock(s.b[i*sha256.Size:], header, uint32(i+1))
+	}
+
+	// B = ROMix(B).
+	for i := range s.x {
+		s.x[i] = binary.LittleEndian.Uint32(s.b[i*4:])
+	}
+	s.romix()
+	for i, w := range s.x {
+		binary.LittleEndian.PutUint32(s.b[i*4:], w)
+	}
+
+	// Hash = PBKDF2-HMAC-SHA256(header, B, 1, 32).
+	// The digest is produced into the scratch space and then copied out
+	// since passing the returned hash to the hasher would force it to
+	// escape to the heap.
+	var hash Hash
+	s.hmacBlock(s.sum[:], s.b[:], 1)
+	copy(hash[:], s.sum[:])
+	return hash
+}
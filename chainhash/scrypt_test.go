@@ -0,0 +1,115 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chainhash
+
+import (
+	"encoding/hex"
+	"sync"
+	"testing"
+)
+
+// scryptTests houses serialized Litecoin main network block headers along
+// with their expected scrypt proof of work hashes.
+var scryptTests = []struct {
+	header string
+	want   string
+}{
+	// Block 29255.
+	{
+		header: "01000000f615f7ce3b4fc6b8f61e8f89aedb1d0852507650533a9e3b" +
+			"10b9bbcc30639f279fcaa86746e1ef52d3edb3c4ad8259920d509bd0" +
+			"73605c9bf1d59983752a6b06b817bb4ea78e011d012d59d4",
+		want: "0000000110c8357966576df46f3b802ca897deb7ad18b12f1c24ecff6386ebd9",
+	},
+	{
+		header: "020000004c1271c211717198227392b029a64a7971931d351b387bb8" +
+			"0db027f270411e398a07046f7d4a08dd815412a8712f874a7ebf0507" +
+			"e3878bd24e20a3b73fd750a667d2f451eac7471b00de6659",
+		want: "00000000002bef4107f882f6115e0b01f348d21195dacd3582aa2dabd7985806",
+	},
+	{
+		header: "0200000011503ee6a855e900c00cfdd98f5f55fffeaee9b6bf55bea9" +
+			"b852d9de2ce35828e204eef76acfd36949ae56d1fbe81c1ac9c0209e" +
+			"6331ad56414f9072506a77f8c6faf551eac7471b00389d01",
+		want: "00000000003a0d11bdd5eb634e08b7feddcfbbf228ed35d250daf19f1c88fc94",
+	},
+	{
+		header: "02000000a72c8a177f523946f42f22c3e86b8023221b4105e8007e59" +
+			"e81f6beb013e29aaf635295cb9ac966213fb56e046dc71df5b3f7f67" +
+			"ceaeab24038e743f883aff1aaafaf551eac7471b0166249b",
+		want: "00000000000b40f895f288e13244728a6c2d9d59d8aff29c65f8dd5114a8ca81",
+	},
+	{
+		header: "010000007824bc3a8a1b4628485eee3024abd8626721f7f870f8ad4d" +
+			"2f33a27155167f6a4009d1285049603888fe85a84b6c803a53305a8d" +
+			"497965a5e896e1a00568359589faf551eac7471b0065434e",
+		want: "00000000003007005891cd4923031e99d8e8d72f6e8e7edc6a86181897e105fe",
+	},
+	{
+		header: "0200000050bfd4e4a307a8cb6ef4aef69abc5c0f2d579648bd80d773" +
+			"3e1ccc3fbc90ed664a7f74006cb11bde87785f229ecd366c2d4e4443" +
+			"2832580e0608c579e4cb76f383f7f551eac7471b00c36982",
+		want: "000000000018f0b426a4afc7130ccb47fa02af730d345b4fe7c7724d3800ec8c",
+	},
+}
+
+// TestScryptPoWHash ensures the scrypt proof of work hash function produces
+// the expected results for known block headers.
+func TestScryptPoWHash(t *testing.T) {
+	for i, test := range scryptTests {
+		header, err := hex.DecodeString(test.header)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error decoding header: %v", i, err)
+		}
+
+		// Hash each header twice to ensure a recycled scratchpad does
+		// not leak state between calls.
+		for j := 0; j < 2; j++ {
+			hash := ScryptPoWHash(header)
+			if hash.String() != test.want {
+				t.Errorf("#%d: ScryptPoWHash = %v, want %v", i,
+					hash, test.want)
+			}
+		}
+	}
+}
+
+// TestScryptPoWHashConcurrent ensures the scrypt proof of work hash function
+// produces the expected results when called from many goroutines at once.
+func TestScryptPoWHashConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i, test := range scryptTests {
+		header, err := hex.DecodeString(test.header)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error decoding header: %v", i, err)
+		}
+
+		wg.Add(1)
+		go func(i int, header []byte, want string) {
+			defer wg.Done()
+			for j := 0; j < 4; j++ {
+				hash := ScryptPoWHash(header)
+				if hash.String() != want {
+					t.Errorf("#%d: ScryptPoWHash = %v, want %v",
+						i, hash, want)
+					return
+				}
+			}
+		}(i, header, test.want)
+	}
+	wg.Wait()
+}
+
+// BenchmarkScryptPoWHash performs a benchmark on how long it takes to compute
+// the scrypt proof of work hash of a block header.
+func BenchmarkScryptPoWHash(b *testing.B) {
+	header, _ := hex.DecodeString(scryptTests[0].header)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ScryptPoWHash(header)
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// These constants define the script opcodes used to build the charity output
+// scripts.
+const (
+	opDup         = 0x76
+	opHash160     = 0xa9
+	opEqualVerify = 0x88
+	opCheckSig    = 0xac
+)
+
+var (
+	// ErrNoCharityPubKey describes an error where a charity script was
+	// requested for a network or height without a charity key.
+	ErrNoCharityPubKey = errors.New("network does not define a charity " +
+		"public key")
+
+	// ErrInvalidCharityPubKey describes an error where the charity public
+	// key of a network is not a hex encoded compressed or uncompressed
+	// public key.
+	ErrInvalidCharityPubKey = errors.New("invalid charity public key")
+
+	// ErrMissingCharityOutput describes an error where a coinbase
+	// transaction does not pay the required amount to the charity.
+	ErrMissingCharityOutput = errors.New("coinbase does not pay the " +
+		"required charity output")
+
+	// ErrCharityKeysNotAscending describes an error where the activation
+	// heights of the charity keys of a network are not strictly
+	// ascending.
+	ErrCharityKeysNotAscending = errors.New("charity key heights are not " +
+		"strictly ascending")
+
+	// ErrCharityRatesNotAscending describes an error where the heights of
+	// the charity rates of a network are not strictly ascending.
+	ErrCharityRatesNotAscending = errors.New("charity rate heights are " +
+		"not strictly ascending")
+
+	// ErrInvalidCharityRate describes an error where a charity rate of a
+	// network is not a percentage between 0 and 100.
+	ErrInvalidCharityRate = errors.New("charity rate is not between 0 and " +
+		"100 percent")
+)
+
+// CharityRate defines the share of the block subsidy that must be paid to the
+// charity from a given height onwards.
+type CharityRate struct {
+	// Height is the height of the first block the rate applies to.
+	Height int32
+
+	// Percent is the percentage of the block subsidy paid to the charity.
+	Percent int64
+}
+
+// CharityKey defines the charity destination coinbase transactions must pay
+// from a given height onwards.  It allows the charity key to be rotated over
+// time.
+type CharityKey struct {
+	// Height is the height of the first block which pays to the key.
+	Height int32
+
+	// PubKey is the hex encoded public key of the charity.  Both the
+	// pay-to-pubkey-hash and pay-to-pubkey scripts paying to it are
+	// accepted.
+	PubKey string
+
+	// Script is the script paying to the charity.  It is only used when
+	// PubKey is empty.
+	Script []byte
+}
+
+// decodeCharityPubKey decodes and sanity checks a hex encoded charity public
+// key.
+func decodeCharityPubKey(pubKeyStr string) ([]byte, error) {
+	pubKey, err := hex.DecodeString(pubKeyStr)
+	if err != nil {
+		return nil, ErrInvalidCharityPubKey
+	}
+
+	switch {
+	case len(pubKey) == 33 && (pubKey[0] == 0x02 || pubKey[0] == 0x03):
+	case len(pubKey) == 65 && pubKey[0] == 0x04:
+	default:
+		return nil, ErrInvalidCharityPubKey
+	}
+	return pubKey, nil
+}
+
+// scripts returns the scripts which pay to the charity key.  The first one is
+// the preferred script.
+func (k *CharityKey) scripts() ([][]byte, error) {
+	if k.PubKey == "" {
+		if len(k.Script) == 0 {
+			return nil, ErrNoCharityPubKey
+		}
+		return [][]byte{k.Script}, nil
+	}
+
+	pubKey, err := decodeCharityPubKey(k.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{
+		payToPubKeyHashScript(pubKey),
+		payToPubKeyScript(pubKey),
+	}, nil
+}
+
+// charityKeys returns the charity key schedule of the network.  Networks which
+// only define CharityPubKey pay to it from the genesis block.
+func (p *Params) charityKeys() []CharityKey {
+	if len(p.CharityKeys) == 0 && p.CharityPubKey != "" {
+		return []CharityKey{{Height: 0, PubKey: p.CharityPubKey}}
+	}
+	return p.CharityKeys
+}
+
+// charityKeyAt returns the charity key that blocks at the passed height pay
+// to, or nil when there is none.
+func (p *Params) charityKeyAt(height int32) *CharityKey {
+	// Find the first key which activates above the height.  The one right
+	// before it, if any, is the active key.
+	keys := p.charityKeys()
+	i := sort.Search(len(keys), func(i int) bool {
+		return keys[i].Height > height
+	})
+	if i == 0 {
+		return nil
+	}
+	return &keys[i-1]
+}
+
+// payToPubKeyHashScript returns a pay-to-pubkey-hash script paying to the
+// hash160 of the passed public key.
+func payToPubKeyHashScript(pubKey []byte) []byte {
+	pubKeyHash := chainhash.Hash160(pubKey)
+
+	script := make([]byte, 0, 25)
+	script = append(script, opDup, opHash160, byte(len(pubKeyHash)))
+	script = append(script, pubKeyHash...)
+	return append(script, opEqualVerify, opCheckSig)
+}
+
+// payToPubKeyScript returns a pay-to-pubkey script paying to the passed public
+// key.
+func payToPubKeyScript(pubKey []byte) []byte {
+	script := make([]byte, 0, len(pubKey)+2)
+	script = append(script, byte(len(pubKey)))
+	script = append(script, pubKey...)
+	return append(script, opCheckSig)
+}
+
+// CharityScriptAt returns the script blocks at the passed height must pay the
+// charity with.  This is the pay-to-pubkey-hash script of the active charity
+// key, or its script when it does not define a public key.
+// ErrNoCharityPubKey is returned when no charity key is active at the height
+// and ErrInvalidCharityPubKey when the active key is malformed.
+func (p *Params) CharityScriptAt(height int32) ([]byte, error) {
+	key := p.charityKeyAt(height)
+	if key == nil {
+		return nil, ErrNoCharityPubKey
+	}
+
+	scripts, err := key.scripts()
+	if err != nil {
+		return nil, err
+	}
+	return scripts[0], nil
+}
+
+// CharityScript returns the pay-to-pubkey-hash script which pays to the most
+// recent charity key of the network.  It returns the same errors as
+// CharityScriptAt.
+func (p *Params) CharityScript() ([]byte, error) {
+	return p.CharityScriptAt(math.MaxInt32)
+}
+
+// CharityPubKeyScript returns the pay-to-pubkey script which pays to the most
+// recent charity key of the network.  ErrNoCharityPubKey is returned when that
+// key does not define a public key.
+func (p *Params) CharityPubKeyScript() ([]byte, error) {
+	key := p.charityKeyAt(math.MaxInt32)
+	if key == nil || key.PubKey == "" {
+		return nil, ErrNoCharityPubKey
+	}
+
+	pubKey, err := decodeCharityPubKey(key.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	return payToPubKeyScript(pubKey), nil
+}
+
+// charityPercent returns the percentage of the subsidy owed to the charity by
+// the block at the passed height.
+func (p *Params) charityPercent(height int32) int64 {
+	// Find the first rate which starts above the height.  The one right
+	// before it, if any, applies to the block.
+	rates := p.CharityRates
+	i := sort.Search(len(rates), func(i int) bool {
+		return rates[i].Height > height
+	})
+	if i == 0 {
+		return 0
+	}
+	return rates[i-1].Percent
+}
+
+// ExpectedCharityOutput returns the amount, in the smallest unit of the coin,
+// the coinbase of the block at the passed height must pay to the charity given
+// the subsidy of the block.  It is zero when no charity key is active or no
+// charity rate applies at the height.
+func (p *Params) ExpectedCharityOutput(height int32, subsidy int64) int64 {
+	if p.charityKeyAt(height) == nil {
+		return 0
+	}
+	return subsidy * p.charityPercent(height) / 100
+}
+
+// ValidateCoinbaseCharity ensures the passed coinbase transaction of the block
+// at the passed height contains an output paying at least the expected charity
+// amount to the charity key active at the height, using either the
+// pay-to-pubkey-hash or pay-to-pubkey script for keys defined by a public key.
+// ErrMissingCharityOutput is returned when it does not.
+//
+// The expected amount is calculated from the subsidy returned by BlockSubsidy,
+// which includes superblocks.
+func (p *Params) ValidateCoinbaseCharity(tx *wire.MsgTx, height int32) error {
+	expected := p.ExpectedCharityOutput(height, p.BlockSubsidy(height))
+	if expected == 0 {
+		return nil
+	}
+
+	scripts, err := p.charityKeyAt(height).scripts()
+	if err != nil {
+		return err
+	}
+
+	for _, txOut := range tx.TxOut {
+		if txOut.Value < expected {
+			continue
+		}
+		for _, script := range scripts {
+			if bytes.Equal(txOut.PkScript, script) {
+				return nil
+			}
+		}
+	}
+
+	return ErrMissingCharityOutput
+}
+
+// validateCharityKeys ensures the activation heights of the charity keys are
+// strictly ascending and every key defines a public key or a script.
+func (p *Params) validateCharityKeys() error {
+	for i := range p.CharityKeys {
+		key := &p.CharityKeys[i]
+		if i > 0 && key.Height <= p.CharityKeys[i-1].Height {
+			return ErrCharityKeysNotAscending
+		}
+		if _, err := key.scripts(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCharityRates ensures the heights of the charity rates are strictly
+// ascending, which charityPercent relies on, and every rate is a percentage
+// between 0 and 100.
+func (p *Params) validateCharityRates() error {
+	rates := p.CharityRates
+	for i, rate := range rates {
+		if i > 0 && rate.Height <= rates[i-1].Height {
+			return ErrCharityRatesNotAscending
+		}
+		if rate.Percent < 0 || rate.Percent > 100 {
+			return ErrInvalidCharityRate
+		}
+	}
+	return nil
+}
+
+// CharityPayout describes the total amount owed to a single charity key over a
+// range of heights.
+type CharityPayout struct {
+	// Key is the charity key which was paid.
+	Key CharityKey
+
+	// FirstHeight and LastHeight are the heights of the first and last
+	// blocks within the report range which paid to the key.
+	FirstHeight int32
+	LastHeight  int32
+
+	// Blocks is the number of blocks which paid to the key.
+	Blocks int32
+
+	// Total is the total amount, in the smallest unit of the coin, owed
+	// to the key.
+	Total int64
+}
+
+// CharityReport returns the total charity amount owed to each charity key by
+// the blocks from startHeight to endHeight, both inclusive, given a function
+// which returns the subsidy of the block at a height.  Keys are reported in the
+// order they were first paid within the range.  A key which appears several
+// times in the schedule is reported once.
+func (p *Params) CharityReport(startHeight, endHeight int32,
+	subsidy func(height int32) int64) []CharityPayout {
+
+	var payouts []CharityPayout
+	index := make(map[string]int)
+	for height := startHeight; height <= endHeight && height >= startHeight; height++ {
+		key := p.charityKeyAt(height)
+		if key == nil {
+			continue
+		}
+
+		id := key.PubKey + "/" + hex.EncodeToString(key.Script)
+		i, ok := index[id]
+		if !ok {
+			i = len(payouts)
+			index[id] = i
+			payouts = append(payouts, CharityPayout{
+				Key:         *key,
+				FirstHeight: height,
+			})
+		}
+
+		payout := &payouts[i]
+		payout.LastHeight = height
+		payout.Blocks++
+		payout.Total += p.ExpectedCharityOutput(height, subsidy(height))
+	}
+
+	return payouts
+}
@@ -0,0 +1,450 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// hexToBytes converts the passed hex string into bytes and will panic if there
+// is an error.  This is only provided for the hard-coded constants so errors in
+// the source code can be detected.  It will only (and must only) be called with
+// hard-coded values.
+func hexToBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in source file: " + s)
+	}
+	return b
+}
+
+// regTestCharityP2PKH and regTestCharityP2PK are the scripts paying to the
+// charity public key of the regression test network.
+var (
+	regTestCharityP2PKH = hexToBytes("76a9148bb75212b17c58478f5e59ce62e3afc2" +
+		"8530378a88ac")
+	regTestCharityP2PK = hexToBytes("210377ba3117d776b40b49a910e869cd32adee4" +
+		"d33578f7bf52e1879ea739c9796caac")
+)
+
+// TestCharityScript ensures the charity scripts are derived from the charity
+// public key and malformed keys are rejected.
+func TestCharityScript(t *testing.T) {
+	uncompressed := "0411db93e1dcdb8a016b49840f8c53bc1eb68a382e97b1482ecad7b" +
+		"148a6909a5cb2e0eaddfb84ccf9744464f82e160bfa9b8b64f9d4c03f999b8" +
+		"643f656b412a3"
+
+	tests := []struct {
+		name   string
+		pubKey string
+		p2pkh  []byte
+		p2pk   []byte
+		err    error
+	}{
+		{
+			name:   "regtest compressed key",
+			pubKey: RegressionNetParams.CharityPubKey,
+			p2pkh:  regTestCharityP2PKH,
+			p2pk:   regTestCharityP2PK,
+		},
+		{
+			name:   "uncompressed key",
+			pubKey: uncompressed,
+			p2pkh: hexToBytes("76a91411b366edfc0a8b66feebae5c2e25a7b6a5d1" +
+				"cf3188ac"),
+			p2pk: hexToBytes("41" + uncompressed + "ac"),
+		},
+		{
+			name:   "no key",
+			pubKey: "",
+			err:    ErrNoCharityPubKey,
+		},
+		{
+			name:   "invalid hex",
+			pubKey: "0377ba3117d776b40b49a910e869cd32adee4d33578f7bf52e1879e",
+			err:    ErrInvalidCharityPubKey,
+		},
+		{
+			name:   "compressed key with bad prefix",
+			pubKey: "0477ba3117d776b40b49a910e869cd32adee4d33578f7bf52e1879ea739c9796ca",
+			err:    ErrInvalidCharityPubKey,
+		},
+		{
+			name:   "wrong length",
+			pubKey: "0377ba3117d776b40b49a910e869cd32adee4d33578f7bf52e1879ea739c9796",
+			err:    ErrInvalidCharityPubKey,
+		},
+	}
+
+	for _, test := range tests {
+		params := Params{CharityPubKey: test.pubKey}
+
+		p2pkh, err := params.CharityScript()
+		if err != test.err {
+			t.Errorf("%s: CharityScript error = %v, want %v",
+				test.name, err, test.err)
+			continue
+		}
+		if !bytes.Equal(p2pkh, test.p2pkh) {
+			t.Errorf("%s: CharityScript = %x, want %x", test.name,
+				p2pkh, test.p2pkh)
+		}
+
+		p2pk, err := params.CharityPubKeyScript()
+		if err != test.err {
+			t.Errorf("%s: CharityPubKeyScript error = %v, want %v",
+				test.name, err, test.err)
+			continue
+		}
+		if !bytes.Equal(p2pk, test.p2pk) {
+			t.Errorf("%s: CharityPubKeyScript = %x, want %x",
+				test.name, p2pk, test.p2pk)
+		}
+	}
+}
+
+// TestExpectedCharityOutput ensures the charity amount follows the charity
+// rate schedule.
+func TestExpectedCharityOutput(t *testing.T) {
+	params := Params{
+		CharityPubKey: RegressionNetParams.CharityPubKey,
+		CharityRates: []CharityRate{
+			{Height: 10, Percent: 2},
+			{Height: 100, Percent: 5},
+			{Height: 200, Percent: 0},
+		},
+	}
+	noKeyParams := params
+	noKeyParams.CharityPubKey = ""
+
+	tests := []struct {
+		name    string
+		params  *Params
+		height  int32
+		subsidy int64
+		want    int64
+	}{
+		{"before first rate", &params, 9, 50 * 1e8, 0},
+		{"first rate", &params, 10, 50 * 1e8, 1e8},
+		{"rounds down", &params, 99, 99, 1},
+		{"second rate", &params, 100, 50 * 1e8, 25 * 1e7},
+		{"rate ended", &params, 200, 50 * 1e8, 0},
+		{"no charity key", &noKeyParams, 10, 50 * 1e8, 0},
+		{"mainnet", &MainNetParams, 10, 50 * 1e8, 0},
+		{"regtest", &RegressionNetParams, 10, 50 * 1e8, 1e8},
+	}
+
+	for _, test := range tests {
+		amount := test.params.ExpectedCharityOutput(test.height,
+			test.subsidy)
+		if amount != test.want {
+			t.Errorf("%s: ExpectedCharityOutput = %d, want %d",
+				test.name, amount, test.want)
+		}
+	}
+}
+
+// TestValidateCoinbaseCharity ensures coinbase transactions are only accepted
+// when they pay the required charity amount to one of the charity scripts.
+func TestValidateCoinbaseCharity(t *testing.T) {
+	// The regression test network pays 2% of the 50 coin subsidy, or one
+	// coin, to the charity.
+	const charity = 1e8
+	minerScript := hexToBytes("76a914000000000000000000000000000000000000" +
+		"000088ac")
+	coinbase := func(outs ...*wire.TxOut) *wire.MsgTx {
+		return &wire.MsgTx{Version: 1, TxOut: outs}
+	}
+
+	tests := []struct {
+		name   string
+		params *Params
+		tx     *wire.MsgTx
+		height int32
+		err    error
+	}{
+		{
+			name:   "p2pkh charity output",
+			params: &RegressionNetParams,
+			tx: coinbase(
+				&wire.TxOut{Value: 49 * 1e8, PkScript: minerScript},
+				&wire.TxOut{Value: charity, PkScript: regTestCharityP2PKH},
+			),
+			height: 1,
+		},
+		{
+			name:   "p2pk charity output",
+			params: &RegressionNetParams,
+			tx: coinbase(
+				&wire.TxOut{Value: charity, PkScript: regTestCharityP2PK},
+				&wire.TxOut{Value: 49 * 1e8, PkScript: minerScript},
+			),
+			height: 1,
+		},
+		{
+			name:   "overpaid charity output",
+			params: &RegressionNetParams,
+			tx: coinbase(
+				&wire.TxOut{Value: 50 * 1e8, PkScript: regTestCharityP2PKH},
+			),
+			height: 1,
+		},
+		{
+			name:   "underpaid charity output",
+			params: &RegressionNetParams,
+			tx: coinbase(
+				&wire.TxOut{Value: 49 * 1e8, PkScript: minerScript},
+				&wire.TxOut{Value: charity - 1, PkScript: regTestCharityP2PKH},
+			),
+			height: 1,
+			err:    ErrMissingCharityOutput,
+		},
+		{
+			name:   "charity split over outputs",
+			params: &RegressionNetParams,
+			tx: coinbase(
+				&wire.TxOut{Value: charity / 2, PkScript: regTestCharityP2PKH},
+				&wire.TxOut{Value: charity / 2, PkScript: regTestCharityP2PK},
+			),
+			height: 1,
+			err:    ErrMissingCharityOutput,
+		},
+		{
+			name:   "no charity output",
+			params: &RegressionNetParams,
+			tx: coinbase(
+				&wire.TxOut{Value: 50 * 1e8, PkScript: minerScript},
+			),
+			height: 1,
+			err:    ErrMissingCharityOutput,
+		},
+		{
+			name:   "no charity on mainnet",
+			params: &MainNetParams,
+			tx: coinbase(
+				&wire.TxOut{Value: 50 * 1e8, PkScript: minerScript},
+			),
+			height: 1,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.params.ValidateCoinbaseCharity(test.tx, test.height)
+		if err != test.err {
+			t.Errorf("%s: ValidateCoinbaseCharity = %v, want %v",
+				test.name, err, test.err)
+		}
+	}
+}
+
+// rotatedCharityParams returns parameters which rotate the charity key twice,
+// the last time to a raw script, and pay 2% of the subsidy from height 10.
+func rotatedCharityParams() *Params {
+	return &Params{
+		CharityKeys: []CharityKey{
+			{Height: 10, PubKey: RegressionNetParams.CharityPubKey},
+			{Height: 20, PubKey: "0411db93e1dcdb8a016b49840f8c53bc1eb68a" +
+				"382e97b1482ecad7b148a6909a5cb2e0eaddfb84ccf9744464f8" +
+				"2e160bfa9b8b64f9d4c03f999b8643f656b412a3"},
+			{Height: 30, Script: []byte{0x51}},
+		},
+		CharityRates: []CharityRate{{Height: 0, Percent: 2}},
+	}
+}
+
+// TestCharityScriptAt ensures the charity script follows the charity key
+// rotation schedule.
+func TestCharityScriptAt(t *testing.T) {
+	params := rotatedCharityParams()
+	secondP2PKH := hexToBytes("76a91411b366edfc0a8b66feebae5c2e25a7b6a5d1" +
+		"cf3188ac")
+
+	tests := []struct {
+		height int32
+		want   []byte
+		err    error
+	}{
+		{height: 0, err: ErrNoCharityPubKey},
+		{height: 9, err: ErrNoCharityPubKey},
+		{height: 10, want: regTestCharityP2PKH},
+		{height: 19, want: regTestCharityP2PKH},
+		{height: 20, want: secondP2PKH},
+		{height: 29, want: secondP2PKH},
+		{height: 30, want: []byte{0x51}},
+		{height: 1 << 30, want: []byte{0x51}},
+	}
+
+	for _, test := range tests {
+		script, err := params.CharityScriptAt(test.height)
+		if err != test.err {
+			t.Errorf("height %d: CharityScriptAt error = %v, want %v",
+				test.height, err, test.err)
+			continue
+		}
+		if !bytes.Equal(script, test.want) {
+			t.Errorf("height %d: CharityScriptAt = %x, want %x",
+				test.height, script, test.want)
+		}
+	}
+
+	// The latest key defines a raw script, so there is no pay-to-pubkey
+	// script for it.
+	if _, err := params.CharityPubKeyScript(); err != ErrNoCharityPubKey {
+		t.Errorf("CharityPubKeyScript error = %v, want %v", err,
+			ErrNoCharityPubKey)
+	}
+
+	// Coinbases must pay the key active at their height.
+	tx := &wire.MsgTx{TxOut: []*wire.TxOut{
+		{Value: 1e8, PkScript: regTestCharityP2PK},
+	}}
+	params.BaseSubsidy = 50 * 1e8
+	if err := params.ValidateCoinbaseCharity(tx, 15); err != nil {
+		t.Errorf("ValidateCoinbaseCharity at height 15 = %v, want nil",
+			err)
+	}
+	err := params.ValidateCoinbaseCharity(tx, 25)
+	if err != ErrMissingCharityOutput {
+		t.Errorf("ValidateCoinbaseCharity at height 25 = %v, want %v",
+			err, ErrMissingCharityOutput)
+	}
+}
+
+// TestValidateCharityKeys ensures malformed charity key schedules are rejected.
+func TestValidateCharityKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []CharityKey
+		err  error
+	}{
+		{
+			name: "no keys",
+		},
+		{
+			name: "ascending",
+			keys: rotatedCharityParams().CharityKeys,
+		},
+		{
+			name: "duplicate height",
+			keys: []CharityKey{
+				{Height: 10, Script: []byte{0x51}},
+				{Height: 10, Script: []byte{0x52}},
+			},
+			err: ErrCharityKeysNotAscending,
+		},
+		{
+			name: "descending",
+			keys: []CharityKey{
+				{Height: 20, Script: []byte{0x51}},
+				{Height: 10, Script: []byte{0x52}},
+			},
+			err: ErrCharityKeysNotAscending,
+		},
+		{
+			name: "invalid public key",
+			keys: []CharityKey{{Height: 0, PubKey: "02"}},
+			err:  ErrInvalidCharityPubKey,
+		},
+		{
+			name: "empty key",
+			keys: []CharityKey{{Height: 0}},
+			err:  ErrNoCharityPubKey,
+		},
+	}
+
+	for _, test := range tests {
+		params := Params{CharityKeys: test.keys}
+		if err := params.validateCharityKeys(); err != test.err {
+			t.Errorf("%s: validateCharityKeys = %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestValidateCharityRates ensures misordered charity rates and rates which
+// are not percentages are rejected.
+func TestValidateCharityRates(t *testing.T) {
+	tests := []struct {
+		name  string
+		rates []CharityRate
+		err   error
+	}{
+		{
+			name: "no rates",
+		},
+		{
+			name:  "ascending",
+			rates: []CharityRate{{0, 5}, {100, 2}},
+		},
+		{
+			name:  "duplicate height",
+			rates: []CharityRate{{10, 5}, {10, 2}},
+			err:   ErrCharityRatesNotAscending,
+		},
+		{
+			name:  "descending",
+			rates: []CharityRate{{20, 5}, {10, 2}},
+			err:   ErrCharityRatesNotAscending,
+		},
+		{
+			name:  "negative percent",
+			rates: []CharityRate{{0, -1}},
+			err:   ErrInvalidCharityRate,
+		},
+		{
+			name:  "above 100 percent",
+			rates: []CharityRate{{0, 101}},
+			err:   ErrInvalidCharityRate,
+		},
+	}
+
+	for _, test := range tests {
+		params := Params{CharityRates: test.rates}
+		if err := params.validateCharityRates(); err != test.err {
+			t.Errorf("%s: validateCharityRates = %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCharityReport ensures the charity report totals the amount owed to each
+// key over the requested range of heights.
+func TestCharityReport(t *testing.T) {
+	params := rotatedCharityParams()
+
+	// Rotate back to the first key so it is paid over two ranges.
+	params.CharityKeys = append(params.CharityKeys, CharityKey{
+		Height: 40,
+		PubKey: RegressionNetParams.CharityPubKey,
+	})
+	keys := params.CharityKeys
+	subsidy := func(height int32) int64 {
+		return 100 * int64(height)
+	}
+
+	report := params.CharityReport(5, 45, subsidy)
+	want := []CharityPayout{
+		// 2% of 100 * (10 + ... + 19) and 100 * (40 + ... + 45).
+		{Key: keys[0], FirstHeight: 10, LastHeight: 45, Blocks: 16,
+			Total: 290 + 510},
+		{Key: keys[1], FirstHeight: 20, LastHeight: 29, Blocks: 10,
+			Total: 490},
+		{Key: keys[2], FirstHeight: 30, LastHeight: 39, Blocks: 10,
+			Total: 690},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("CharityReport = %+v, want %+v", report, want)
+	}
+
+	if report := params.CharityReport(0, 9, subsidy); len(report) != 0 {
+		t.Errorf("CharityReport before first key = %+v, want none",
+			report)
+	}
+}
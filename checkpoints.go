@@ -0,0 +1,225 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+var (
+	// ErrNotCheckpoint describes an error where a block is verified
+	// against the checkpoints of a network although there is no checkpoint
+	// at its height.
+	ErrNotCheckpoint = errors.New("no checkpoint at height")
+
+	// ErrCheckpointMismatch describes an error where the hash of a block
+	// at a checkpoint height does not match the checkpoint.
+	ErrCheckpointMismatch = errors.New("block hash does not match " +
+		"checkpoint")
+
+	// ErrCheckpointsNotAscending describes an error where the heights of
+	// the checkpoints of a network are not strictly ascending.
+	ErrCheckpointsNotAscending = errors.New("checkpoint heights are not " +
+		"strictly ascending")
+
+	// ErrDuplicateCheckpoint describes an error where a network defines
+	// more than one checkpoint with the same hash.
+	ErrDuplicateCheckpoint = errors.New("duplicate checkpoint hash")
+
+	// ErrMissingCheckpointHash describes an error where a network defines
+	// a checkpoint without a hash.
+	ErrMissingCheckpointHash = errors.New("checkpoint has no hash")
+)
+
+// LatestCheckpoint returns the most recent checkpoint of the network, or nil
+// when it does not define any.
+func (p *Params) LatestCheckpoint() *Checkpoint {
+	if len(p.Checkpoints) == 0 {
+		return nil
+	}
+	return &p.Checkpoints[len(p.Checkpoints)-1]
+}
+
+// CheckpointAt returns the checkpoint at the passed height, or nil when there
+// is none.
+func (p *Params) CheckpointAt(height int32) *Checkpoint {
+	checkpoints := p.Checkpoints
+	i := sort.Search(len(checkpoints), func(i int) bool {
+		return checkpoints[i].Height >= height
+	})
+	if i == len(checkpoints) || checkpoints[i].Height != height {
+		return nil
+	}
+	return &checkpoints[i]
+}
+
+// CheckpointBefore returns the most recent checkpoint below the passed height,
+// or nil when there is none.
+func (p *Params) CheckpointBefore(height int32) *Checkpoint {
+	checkpoints := p.Checkpoints
+	i := sort.Search(len(checkpoints), func(i int) bool {
+		return checkpoints[i].Height >= height
+	})
+	if i == 0 {
+		return nil
+	}
+	return &checkpoints[i-1]
+}
+
+// VerifyCheckpoint ensures the block with the passed hash at the passed height
+// matches the checkpoint at that height.  ErrNotCheckpoint is returned when
+// there is no checkpoint at the height and ErrCheckpointMismatch when the hash
+// differs from the checkpoint.
+func (p *Params) VerifyCheckpoint(height int32, hash *chainhash.Hash) error {
+	checkpoint := p.CheckpointAt(height)
+	if checkpoint == nil {
+		return ErrNotCheckpoint
+	}
+	if !checkpoint.Hash.IsEqual(hash) {
+		return ErrCheckpointMismatch
+	}
+	return nil
+}
+
+// validateCheckpoints ensures the heights of the checkpoints of the network are
+// strictly ascending and every checkpoint has a unique hash.
+func (p *Params) validateCheckpoints() error {
+	seen := make(map[chainhash.Hash]struct{}, len(p.Checkpoints))
+	for i, checkpoint := range p.Checkpoints {
+		if i > 0 && checkpoint.Height <= p.Checkpoints[i-1].Height {
+			return ErrCheckpointsNotAscending
+		}
+		if checkpoint.Hash == nil {
+			return ErrMissingCheckpointHash
+		}
+		if _, ok := seen[*checkpoint.Hash]; ok {
+			return ErrDuplicateCheckpoint
+		}
+		seen[*checkpoint.Hash] = struct{}{}
+	}
+	return nil
+}
+
+// DefaultCheckpointConfirmations is the default number of blocks which must be
+// built on top of a block before it is considered as a checkpoint candidate.
+const DefaultCheckpointConfirmations = 2016
+
+// CheckpointCandidateConfig houses the rules used to select checkpoint
+// candidates.  See CheckpointCandidates.
+type CheckpointCandidateConfig struct {
+	// Confirmations is the minimum number of blocks which must be built on
+	// top of a candidate.  DefaultCheckpointConfirmations is used when it
+	// is zero.
+	Confirmations int32
+
+	// MinSpacing is the minimum number of blocks between two selected
+	// candidates.  Every eligible block is selected when it is zero.
+	MinSpacing int32
+
+	// AfterHeight only considers blocks above this height.  Refreshing
+	// the checkpoints of a network typically sets it to the height of its
+	// latest checkpoint.
+	AfterHeight int32
+
+	// FirstHeight is the height of the first block the header provider
+	// knows of, such as the start of a header dump.  Since a candidate is
+	// compared with the block before it, only blocks above this height are
+	// considered.
+	FirstHeight int32
+}
+
+// IsCheckpointCandidate returns whether the block at the passed height of the
+// chain with the passed tip height is a suitable checkpoint.  The block must
+// have at least the passed number of blocks built on top of it, must not be
+// the genesis block and its timestamp must be in order with the timestamps of
+// the blocks on either side of it, which is not always the case due to the
+// median time allowance.
+func IsCheckpointCandidate(headers HeaderProvider, height, tipHeight,
+	confirmations int32) (bool, error) {
+
+	// A checkpoint must be at least confirmations blocks before the end
+	// of the main chain and have at least one block before and after it.
+	if height <= 0 || height > tipHeight-confirmations ||
+		height >= tipHeight {
+
+		return false, nil
+	}
+
+	prev, err := headers.HeaderByHeight(height - 1)
+	if err != nil {
+		return false, err
+	}
+	cur, err := headers.HeaderByHeight(height)
+	if err != nil {
+		return false, err
+	}
+	next, err := headers.HeaderByHeight(height + 1)
+	if err != nil {
+		return false, err
+	}
+
+	// A checkpoint must have timestamps for the block and the blocks on
+	// either side of it in order.
+	if prev.Timestamp.After(cur.Timestamp) ||
+		next.Timestamp.Before(cur.Timestamp) {
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// CheckpointCandidates selects checkpoints from the chain with the passed tip
+// height according to the passed config.  Candidates are selected in
+// ascending height order, each being the first block satisfying
+// IsCheckpointCandidate at least MinSpacing blocks after the previously
+// selected one.
+func CheckpointCandidates(headers HeaderProvider, tipHeight int32,
+	cfg CheckpointCandidateConfig) ([]Checkpoint, error) {
+
+	confirmations := cfg.Confirmations
+	if confirmations == 0 {
+		confirmations = DefaultCheckpointConfirmations
+	}
+
+	// Start above both the requested height and the first known block.
+	startHeight := cfg.AfterHeight + 1
+	if cfg.FirstHeight >= startHeight {
+		startHeight = cfg.FirstHeight + 1
+	}
+
+	var candidates []Checkpoint
+	lastHeight := tipHeight - confirmations
+	for height := startHeight; height <= lastHeight; height++ {
+		ok, err := IsCheckpointCandidate(headers, height, tipHeight,
+			confirmations)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		header, err := headers.HeaderByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		hash := header.BlockHash()
+		candidates = append(candidates, Checkpoint{
+			Height: height,
+			Hash:   &hash,
+		})
+
+		// Skip the blocks which are too close to the selected one.
+		if cfg.MinSpacing > 1 {
+			height += cfg.MinSpacing - 1
+		}
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// checkpointTestParams returns parameters with checkpoints at heights 10, 20
+// and 30.
+func checkpointTestParams() *Params {
+	return &Params{
+		Checkpoints: []Checkpoint{
+			{10, newHashFromStr("0a")},
+			{20, newHashFromStr("14")},
+			{30, newHashFromStr("1e")},
+		},
+	}
+}
+
+// TestCheckpointQueries ensures checkpoints are found by height.
+func TestCheckpointQueries(t *testing.T) {
+	params := checkpointTestParams()
+
+	// height returns the height of the passed checkpoint or -1 when it is
+	// nil.
+	height := func(checkpoint *Checkpoint) int32 {
+		if checkpoint == nil {
+			return -1
+		}
+		return checkpoint.Height
+	}
+
+	if got := height(params.LatestCheckpoint()); got != 30 {
+		t.Errorf("LatestCheckpoint height = %d, want 30", got)
+	}
+	if got := height((&Params{}).LatestCheckpoint()); got != -1 {
+		t.Errorf("LatestCheckpoint height without checkpoints = %d, "+
+			"want none", got)
+	}
+
+	tests := []struct {
+		height int32
+		at     int32
+		before int32
+	}{
+		{0, -1, -1},
+		{10, 10, -1},
+		{11, -1, 10},
+		{20, 20, 10},
+		{25, -1, 20},
+		{30, 30, 20},
+		{31, -1, 30},
+	}
+
+	for _, test := range tests {
+		if got := height(params.CheckpointAt(test.height)); got != test.at {
+			t.Errorf("CheckpointAt(%d) height = %d, want %d",
+				test.height, got, test.at)
+		}
+		got := height(params.CheckpointBefore(test.height))
+		if got != test.before {
+			t.Errorf("CheckpointBefore(%d) height = %d, want %d",
+				test.height, got, test.before)
+		}
+	}
+}
+
+// TestVerifyCheckpoint ensures mismatching blocks are distinguished from blocks
+// which are not at a checkpoint height.
+func TestVerifyCheckpoint(t *testing.T) {
+	params := checkpointTestParams()
+
+	tests := []struct {
+		height int32
+		hash   string
+		err    error
+	}{
+		{20, "14", nil},
+		{20, "15", ErrCheckpointMismatch},
+		{21, "14", ErrNotCheckpoint},
+	}
+
+	for _, test := range tests {
+		err := params.VerifyCheckpoint(test.height,
+			newHashFromStr(test.hash))
+		if err != test.err {
+			t.Errorf("VerifyCheckpoint(%d, %s) = %v, want %v",
+				test.height, test.hash, err, test.err)
+		}
+	}
+}
+
+// TestValidateCheckpoints ensures checkpoints must be strictly ascending and
+// unique.
+func TestValidateCheckpoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		checkpoints []Checkpoint
+		err         error
+	}{
+		{
+			name:        "ascending",
+			checkpoints: checkpointTestParams().Checkpoints,
+		},
+		{
+			name: "descending",
+			checkpoints: []Checkpoint{
+				{20, newHashFromStr("14")},
+				{10, newHashFromStr("0a")},
+			},
+			err: ErrCheckpointsNotAscending,
+		},
+		{
+			name: "duplicate height",
+			checkpoints: []Checkpoint{
+				{10, newHashFromStr("0a")},
+				{10, newHashFromStr("0b")},
+			},
+			err: ErrCheckpointsNotAscending,
+		},
+		{
+			name: "duplicate hash",
+			checkpoints: []Checkpoint{
+				{10, newHashFromStr("0a")},
+				{20, newHashFromStr("0a")},
+			},
+			err: ErrDuplicateCheckpoint,
+		},
+		{
+			name:        "missing hash",
+			checkpoints: []Checkpoint{{10, nil}},
+			err:         ErrMissingCheckpointHash,
+		},
+	}
+
+	for _, test := range tests {
+		params := Params{Checkpoints: test.checkpoints}
+		if err := params.validateCheckpoints(); err != test.err {
+			t.Errorf("%s: validateCheckpoints = %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// partialHeaders implements the HeaderProvider interface for the headers of a
+// chain from a given height onwards only.
+type partialHeaders struct {
+	headers     mockHeaders
+	firstHeight int32
+}
+
+// HeaderByHeight returns the header at the passed height, which must not be
+// below the first height.
+//
+// This is part of the HeaderProvider interface.
+func (p *partialHeaders) HeaderByHeight(height int32) (*wire.BlockHeader, error) {
+	if height < p.firstHeight {
+		return nil, fmt.Errorf("no header at height %d", height)
+	}
+	return p.headers.HeaderByHeight(height)
+}
+
+// TestCheckpointCandidates ensures checkpoint candidates honour the depth,
+// timestamp ordering and spacing rules, and are only selected from the known
+// headers.
+func TestCheckpointCandidates(t *testing.T) {
+	headers := make(mockHeaders, 31)
+	for i := range headers {
+		headers[i] = wire.BlockHeader{
+			Timestamp: time.Unix(1500000000+int64(i)*60, 0),
+			Nonce:     uint32(i),
+		}
+	}
+
+	// Move the timestamp of block 12 before the one of block 11, which
+	// disqualifies both of them.
+	headers[12].Timestamp = headers[10].Timestamp
+
+	tests := []struct {
+		name string
+		cfg  CheckpointCandidateConfig
+		want []int32
+	}{
+		{
+			name: "every eligible block",
+			cfg:  CheckpointCandidateConfig{Confirmations: 15},
+			want: []int32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 13, 14, 15},
+		},
+		{
+			name: "spacing",
+			cfg: CheckpointCandidateConfig{
+				Confirmations: 5,
+				MinSpacing:    5,
+			},
+			want: []int32{1, 6, 13, 18, 23},
+		},
+		{
+			name: "after latest checkpoint",
+			cfg: CheckpointCandidateConfig{
+				Confirmations: 5,
+				MinSpacing:    5,
+				AfterHeight:   10,
+			},
+			want: []int32{13, 18, 23},
+		},
+		{
+			// Only the headers from height 20 onwards are known,
+			// as with a header dump which does not start at the
+			// genesis block.
+			name: "header dump start",
+			cfg: CheckpointCandidateConfig{
+				Confirmations: 5,
+				FirstHeight:   20,
+			},
+			want: []int32{21, 22, 23, 24, 25},
+		},
+		{
+			name: "default confirmations",
+			cfg:  CheckpointCandidateConfig{},
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		provider := &partialHeaders{headers, test.cfg.FirstHeight}
+		candidates, err := CheckpointCandidates(provider, 30, test.cfg)
+		if err != nil {
+			t.Errorf("%s: CheckpointCandidates unexpected error: %v",
+				test.name, err)
+			continue
+		}
+		if len(candidates) != len(test.want) {
+			t.Errorf("%s: got %d candidates, want %d", test.name,
+				len(candidates), len(test.want))
+			continue
+		}
+		for i, candidate := range candidates {
+			hash := headers[test.want[i]].BlockHash()
+			if candidate.Height != test.want[i] ||
+				!candidate.Hash.IsEqual(&hash) {
+
+				t.Errorf("%s: candidate #%d = %d %v, want %d %v",
+					test.name, i, candidate.Height,
+					candidate.Hash, test.want[i], hash)
+			}
+		}
+	}
+}
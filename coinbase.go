@@ -0,0 +1,188 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// These constants define the script opcodes used to encode the block height in
+// the signature script of a coinbase transaction.
+const (
+	op0         = 0x00
+	opPushData1 = 0x4c
+	opPushData2 = 0x4d
+	opPushData4 = 0x4e
+	op1Negate   = 0x4f
+	op1         = 0x51
+	op16        = 0x60
+)
+
+// maxCoinbaseHeightLen is the maximum length of the serialized height pushed
+// by a coinbase.  Four bytes hold every non-negative 32-bit height.
+const maxCoinbaseHeightLen = 4
+
+var (
+	// ErrNoCoinbase describes an error where a block which must commit
+	// to its height does not contain a coinbase transaction.
+	ErrNoCoinbase = errors.New("block does not contain a coinbase " +
+		"transaction")
+
+	// ErrMissingCoinbaseHeight describes an error where the signature
+	// script of a coinbase transaction does not start with a push of the
+	// block height.
+	ErrMissingCoinbaseHeight = errors.New("coinbase signature script does " +
+		"not start with a serialized height")
+
+	// ErrNonMinimalCoinbaseHeight describes an error where the block
+	// height in the signature script of a coinbase transaction is not
+	// pushed in the smallest possible way.
+	ErrNonMinimalCoinbaseHeight = errors.New("coinbase height is not " +
+		"minimally encoded")
+
+	// ErrInvalidCoinbaseHeight describes an error where the serialized
+	// height in the signature script of a coinbase transaction is negative
+	// or does not fit in 32 bits.
+	ErrInvalidCoinbaseHeight = errors.New("coinbase height is out of range")
+
+	// ErrBadCoinbaseHeight describes an error where the height serialized
+	// in the coinbase transaction of a block does not match the height of
+	// the block.
+	ErrBadCoinbaseHeight = errors.New("coinbase height does not match the " +
+		"block height")
+)
+
+// EncodeCoinbaseHeight returns the script which pushes the passed block height
+// the way the reference implementation requires it at the start of the
+// signature script of a coinbase transaction.  This is the minimal push of the
+// height: OP_0 for zero, OP_1 through OP_16 for small heights and a push of
+// the little-endian script number otherwise.
+func EncodeCoinbaseHeight(height int32) []byte {
+	switch {
+	case height == 0:
+		return []byte{op0}
+	case height == -1:
+		return []byte{op1Negate}
+	case height >= 1 && height <= 16:
+		return []byte{byte(op1 - 1 + height)}
+	}
+
+	// Serialize the height as a little-endian script number.  The most
+	// significant bit of the last byte is the sign bit, so an extra byte
+	// is added when the magnitude already uses it.
+	n := int64(height)
+	isNegative := n < 0
+	if isNegative {
+		n = -n
+	}
+	var num []byte
+	for n > 0 {
+		num = append(num, byte(n&0xff))
+		n >>= 8
+	}
+	if num[len(num)-1]&0x80 != 0 {
+		extra := byte(0x00)
+		if isNegative {
+			extra = 0x80
+		}
+		num = append(num, extra)
+	} else if isNegative {
+		num[len(num)-1] |= 0x80
+	}
+
+	script := make([]byte, 0, len(num)+1)
+	script = append(script, byte(len(num)))
+	return append(script, num...)
+}
+
+// ExtractCoinbaseHeight returns the block height pushed at the start of the
+// passed coinbase signature script.  The height must be pushed as done by
+// EncodeCoinbaseHeight.  ErrMissingCoinbaseHeight is returned when the script
+// does not start with a push, ErrNonMinimalCoinbaseHeight when the push is
+// not minimal and ErrInvalidCoinbaseHeight when the height is negative or
+// does not fit in 32 bits.
+func ExtractCoinbaseHeight(sigScript []byte) (int32, error) {
+	if len(sigScript) == 0 {
+		return 0, ErrMissingCoinbaseHeight
+	}
+
+	opcode := sigScript[0]
+	switch {
+	case opcode == op0:
+		return 0, nil
+	case opcode >= op1 && opcode <= op16:
+		return int32(opcode - (op1 - 1)), nil
+	case opcode == op1Negate:
+		return 0, ErrInvalidCoinbaseHeight
+	case opcode == opPushData1 || opcode == opPushData2 ||
+		opcode == opPushData4:
+
+		// Heights are short enough to always use a direct push.
+		return 0, ErrNonMinimalCoinbaseHeight
+	case opcode > opPushData4:
+		return 0, ErrMissingCoinbaseHeight
+	}
+
+	// The opcode is a direct push of the serialized height.
+	serializedLen := int(opcode)
+	if len(sigScript[1:]) < serializedLen {
+		return 0, ErrMissingCoinbaseHeight
+	}
+	num := sigScript[1 : serializedLen+1]
+
+	// The most significant byte may only be zero, ignoring the sign bit,
+	// when it is needed to hold the sign bit of the next byte down.
+	last := num[len(num)-1]
+	if last&0x7f == 0 && (len(num) == 1 || num[len(num)-2]&0x80 == 0) {
+		return 0, ErrNonMinimalCoinbaseHeight
+	}
+	if len(num) > maxCoinbaseHeightLen || last&0x80 != 0 {
+		return 0, ErrInvalidCoinbaseHeight
+	}
+
+	var height int32
+	for i, b := range num {
+		height |= int32(b) << uint(8*i)
+	}
+
+	// Heights which have a dedicated opcode must use it.
+	if height <= 16 {
+		return 0, ErrNonMinimalCoinbaseHeight
+	}
+
+	return height, nil
+}
+
+// CheckCoinbaseHeight ensures the coinbase transaction of the passed block,
+// which is at the passed height, starts its signature script with the
+// serialized block height as required by BIP0034.  Blocks before the
+// ForkBIP0034 fork is active are not checked.  ErrBadCoinbaseHeight is
+// returned when the serialized height differs from the height of the block.
+func (p *Params) CheckCoinbaseHeight(block *wire.MsgBlock, height int32) error {
+	if !p.IsActive(ForkBIP0034, height) {
+		return nil
+	}
+
+	if len(block.Transactions) == 0 {
+		return ErrNoCoinbase
+	}
+	coinbase := block.Transactions[0]
+	if len(coinbase.TxIn) == 0 {
+		return ErrNoCoinbase
+	}
+
+	serializedHeight, err := ExtractCoinbaseHeight(
+		coinbase.TxIn[0].SignatureScript)
+	if err != nil {
+		return err
+	}
+	if serializedHeight != height {
+		return ErrBadCoinbaseHeight
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// TestCoinbaseHeightEncoding ensures block heights are encoded minimally and
+// round trip through ExtractCoinbaseHeight.
+func TestCoinbaseHeightEncoding(t *testing.T) {
+	tests := []struct {
+		height  int32
+		encoded string
+	}{
+		{0, "00"},
+		{1, "51"},
+		{16, "60"},
+		{17, "0111"},
+		{127, "017f"},
+		{128, "028000"},
+		{255, "02ff00"},
+		{256, "020001"},
+		{32767, "02ff7f"},
+		{32768, "03008000"},
+		{227836, "03fc7903"}, // First BIP0034 block on Bitcoin.
+		{8388608, "0400008000"},
+		{math.MaxInt32, "04ffffff7f"},
+	}
+
+	for _, test := range tests {
+		want := hexToBytes(test.encoded)
+		encoded := EncodeCoinbaseHeight(test.height)
+		if !bytes.Equal(encoded, want) {
+			t.Errorf("EncodeCoinbaseHeight(%d) = %x, want %x",
+				test.height, encoded, want)
+			continue
+		}
+
+		// Trailing data after the height must be ignored.
+		sigScript := append(encoded, 0x08, 0xde, 0xad, 0xbe, 0xef)
+		height, err := ExtractCoinbaseHeight(sigScript)
+		if err != nil {
+			t.Errorf("ExtractCoinbaseHeight(%x) unexpected error: %v",
+				sigScript, err)
+			continue
+		}
+		if height != test.height {
+			t.Errorf("ExtractCoinbaseHeight(%x) = %d, want %d",
+				sigScript, height, test.height)
+		}
+	}
+}
+
+// TestExtractCoinbaseHeightErrors ensures malformed and non-minimal height
+// pushes are rejected.
+func TestExtractCoinbaseHeightErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		sigScript string
+		err       error
+	}{
+		{"empty script", "", ErrMissingCoinbaseHeight},
+		{"not a push", "76a9", ErrMissingCoinbaseHeight},
+		{"truncated push", "03fc79", ErrMissingCoinbaseHeight},
+		{"pushdata1", "4c03fc7903", ErrNonMinimalCoinbaseHeight},
+		{"small height as data", "0110", ErrNonMinimalCoinbaseHeight},
+		{"zero as data", "0100", ErrNonMinimalCoinbaseHeight},
+		{"padded height", "04fc790300", ErrNonMinimalCoinbaseHeight},
+		{"padded small height", "021100", ErrNonMinimalCoinbaseHeight},
+		{"negative one", "4f", ErrInvalidCoinbaseHeight},
+		{"negative height", "0191", ErrInvalidCoinbaseHeight},
+		{"too large", "050000008000", ErrInvalidCoinbaseHeight},
+	}
+
+	for _, test := range tests {
+		sigScript := hexToBytes(test.sigScript)
+		_, err := ExtractCoinbaseHeight(sigScript)
+		if err != test.err {
+			t.Errorf("%s: ExtractCoinbaseHeight(%x) error = %v, "+
+				"want %v", test.name, sigScript, err, test.err)
+		}
+	}
+}
+
+// TestCheckCoinbaseHeight ensures the coinbase height is only enforced from
+// the BIP0034 activation height.
+func TestCheckCoinbaseHeight(t *testing.T) {
+	block := func(sigScript []byte) *wire.MsgBlock {
+		coinbase := wire.NewMsgTx(1)
+		coinbase.AddTxIn(&wire.TxIn{SignatureScript: sigScript})
+		return &wire.MsgBlock{Transactions: []*wire.MsgTx{coinbase}}
+	}
+
+	tests := []struct {
+		name   string
+		params *Params
+		block  *wire.MsgBlock
+		height int32
+		err    error
+	}{
+		{
+			name:   "matching height",
+			params: &MainNetParams,
+			block:  block(hexToBytes("03fc790304deadbeef")),
+			height: 227836,
+		},
+		{
+			name:   "mismatched height",
+			params: &MainNetParams,
+			block:  block(hexToBytes("03fc7903")),
+			height: 227837,
+			err:    ErrBadCoinbaseHeight,
+		},
+		{
+			name:   "non-minimal height",
+			params: &MainNetParams,
+			block:  block(hexToBytes("04fc790300")),
+			height: 227836,
+			err:    ErrNonMinimalCoinbaseHeight,
+		},
+		{
+			name:   "genesis block is not checked",
+			params: &MainNetParams,
+			block:  MainNetParams.GenesisBlock,
+			height: 0,
+		},
+		{
+			name:   "no coinbase",
+			params: &MainNetParams,
+			block:  &wire.MsgBlock{},
+			height: 1,
+			err:    ErrNoCoinbase,
+		},
+		{
+			name:   "not active on regtest",
+			params: &RegressionNetParams,
+			block:  block(hexToBytes("04deadbeef")),
+			height: 1000,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.params.CheckCoinbaseHeight(test.block, test.height)
+		if err != test.err {
+			t.Errorf("%s: CheckCoinbaseHeight = %v, want %v",
+				test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import "errors"
+
+var (
+	// ErrDuplicateDeployment describes an error where a network defines
+	// more than one deployment with the same name.
+	ErrDuplicateDeployment = errors.New("duplicate deployment")
+
+	// ErrUnnamedDeployment describes an error where a network defines a
+	// deployment without a name.
+	ErrUnnamedDeployment = errors.New("deployment has no name")
+)
+
+// deploymentIDNames maps the deployment IDs defined before deployments were
+// identified by name to the names of the deployments they refer to.
+var deploymentIDNames = [DefinedDeployments]string{
+	DeploymentTestDummy: DeploymentNameTestDummy,
+	DeploymentCSV:       DeploymentNameCSV,
+	DeploymentSegwit:    DeploymentNameSegwit,
+	DeploymentTaproot:   DeploymentNameTaproot,
+}
+
+// Deployment returns the deployment of the network with the passed name and
+// whether the network defines it.
+func (p *Params) Deployment(name string) (*ConsensusDeployment, bool) {
+	for i := range p.Deployments {
+		if p.Deployments[i].Name == name {
+			return &p.Deployments[i], true
+		}
+	}
+	return nil, false
+}
+
+// DeploymentByID returns the deployment of the network referred to by the
+// passed deployment ID, such as DeploymentCSV.  ErrUnknownDeployment is
+// returned when the ID is not known or the network does not define the
+// deployment.
+func (p *Params) DeploymentByID(id uint32) (*ConsensusDeployment, error) {
+	if id >= DefinedDeployments {
+		return nil, ErrUnknownDeployment
+	}
+	deployment, ok := p.Deployment(deploymentIDNames[id])
+	if !ok {
+		return nil, ErrUnknownDeployment
+	}
+	return deployment, nil
+}
+
+// ForEachDeployment calls the passed function with each deployment of the
+// network in the order they are defined.  Iteration stops early when the
+// function returns false.
+func (p *Params) ForEachDeployment(fn func(deployment *ConsensusDeployment) bool) {
+	for i := range p.Deployments {
+		if !fn(&p.Deployments[i]) {
+			return
+		}
+	}
+}
+
+// validateDeployments ensures every deployment of the network has a unique
+// name and consistent BIP0008 fields.
+func (p *Params) validateDeployments() error {
+	seen := make(map[string]struct{}, len(p.Deployments))
+	for i := range p.Deployments {
+		deployment := &p.Deployments[i]
+		if deployment.Name == "" {
+			return ErrUnnamedDeployment
+		}
+		if _, ok := seen[deployment.Name]; ok {
+			return ErrDuplicateDeployment
+		}
+		seen[deployment.Name] = struct{}{}
+
+		// The BIP0008 fields require a timeout height, without which
+		// the deployment would silently be time based.
+		if !deployment.IsHeightBased() && (deployment.LockInOnTimeout ||
+			deployment.StartHeight != 0) {
+
+			return ErrInvalidDeployment
+		}
+		if deployment.IsHeightBased() &&
+			deployment.TimeoutHeight <= deployment.StartHeight {
+
+			return ErrInvalidDeployment
+		}
+	}
+	return nil
+}
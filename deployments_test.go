@@ -0,0 +1,119 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDeploymentRegistry ensures deployments can be looked up by name and by
+// their compatibility IDs on every default network.
+func TestDeploymentRegistry(t *testing.T) {
+	networks := []*Params{
+		&MainNetParams,
+		&RegressionNetParams,
+		&TestNet4Params,
+		&SimNetParams,
+	}
+
+	for _, params := range networks {
+		if err := params.Validate(); err != nil {
+			t.Errorf("%s: Validate unexpected error: %v", params.Name,
+				err)
+		}
+
+		for id := uint32(0); id < DefinedDeployments; id++ {
+			deployment, err := params.DeploymentByID(id)
+			if err != nil {
+				t.Errorf("%s: DeploymentByID(%d) unexpected "+
+					"error: %v", params.Name, id, err)
+				continue
+			}
+			named, ok := params.Deployment(deploymentIDNames[id])
+			if !ok || named != deployment {
+				t.Errorf("%s: Deployment(%q) does not match "+
+					"DeploymentByID(%d)", params.Name,
+					deploymentIDNames[id], id)
+			}
+		}
+
+		segwit, ok := params.Deployment(DeploymentNameSegwit)
+		if !ok || segwit.BitNumber != 1 {
+			t.Errorf("%s: unexpected segwit deployment %+v",
+				params.Name, segwit)
+		}
+	}
+
+	if _, ok := MainNetParams.Deployment("unknown"); ok {
+		t.Errorf("Deployment found an unknown deployment")
+	}
+	_, err := MainNetParams.DeploymentByID(DefinedDeployments)
+	if err != ErrUnknownDeployment {
+		t.Errorf("DeploymentByID error = %v, want %v", err,
+			ErrUnknownDeployment)
+	}
+}
+
+// TestForEachDeployment ensures deployments are iterated in definition order
+// and iteration stops when requested.
+func TestForEachDeployment(t *testing.T) {
+	var names []string
+	MainNetParams.ForEachDeployment(func(d *ConsensusDeployment) bool {
+		names = append(names, d.Name)
+		return true
+	})
+	want := []string{DeploymentNameTestDummy, DeploymentNameCSV,
+		DeploymentNameSegwit, DeploymentNameTaproot}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("ForEachDeployment visited %v, want %v", names, want)
+	}
+
+	var visited int
+	MainNetParams.ForEachDeployment(func(d *ConsensusDeployment) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Errorf("ForEachDeployment visited %d deployments after "+
+			"stopping, want 1", visited)
+	}
+}
+
+// TestValidateDeploymentNames ensures deployments must have unique names.
+func TestValidateDeploymentNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		deployments []ConsensusDeployment
+		err         error
+	}{
+		{
+			name: "unique names",
+			deployments: []ConsensusDeployment{
+				{Name: "a"}, {Name: "b"},
+			},
+		},
+		{
+			name: "duplicate names",
+			deployments: []ConsensusDeployment{
+				{Name: "a"}, {Name: "a"},
+			},
+			err: ErrDuplicateDeployment,
+		},
+		{
+			name:        "unnamed",
+			deployments: []ConsensusDeployment{{}},
+			err:         ErrUnnamedDeployment,
+		},
+	}
+
+	for _, test := range tests {
+		params := Params{Deployments: test.deployments}
+		if err := params.validateDeployments(); err != test.err {
+			t.Errorf("%s: validateDeployments = %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+var (
+	// oneLsh256 is 1 shifted left 256 bits.  It is defined here to avoid
+	// the overhead of creating it multiple times.
+	oneLsh256 = new(big.Int).Lsh(bigOne, 256)
+)
+
+var (
+	// ErrUnexpectedDifficulty describes an error where the target
+	// difficulty encoded in a block's bits is not positive or is higher
+	// than the proof of work limit of the network.
+	ErrUnexpectedDifficulty = errors.New("block target difficulty is out " +
+		"of range")
+
+	// ErrHighHash describes an error where the hash of a block is higher
+	// than the target difficulty it claims.
+	ErrHighHash = errors.New("block hash is higher than expected max")
+)
+
+// HashToBig converts a chainhash.Hash into a big.Int that can be used to
+// perform math comparisons.
+func HashToBig(hash *chainhash.Hash) *big.Int {
+	// A Hash is in little-endian, but the big package wants the bytes in
+	// big-endian, so reverse them.
+	buf := *hash
+	blen := len(buf)
+	for i := 0; i < blen/2; i++ {
+		buf[i], buf[blen-1-i] = buf[blen-1-i], buf[i]
+	}
+
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// CompactToBig converts a compact representation of a whole number N to a
+// big integer.  The representation is similar to IEEE754 floating
+// point numbers.
+//
+// Like IEEE754 floating point, there are three basic components: the sign,
+// the exponent, and the mantissa.  The most significant 8 bits represent the
+// unsigned base 256 exponent, bit 23 (the 24th bit) represents the sign bit and
+// the least significant 23 bits represent the mantissa:
+//
+//	-------------------------------------------------
+//	|   Exponent     |    Sign    |    Mantissa     |
+//	-------------------------------------------------
+//	| 8 bits [31-24] | 1 bit [23] | 23 bits [22-00] |
+//	-------------------------------------------------
+//
+// The formula to calculate N is:
+//
+//	N = (-1^sign) * mantissa * 256^(exponent-3)
+//
+// This compact form is only used in Einsteinium to encode unsigned 256-bit
+// numbers which represent difficulty targets, thus there really is not a need
+// for a sign bit, but it is implemented here to stay consistent with the
+// reference implementation.
+//
+// Encodings whose value does not fit in 256 bits, which the reference
+// implementation reports as overflowing, are returned as the full value so
+// callers comparing against a proof of work limit will reject them.
+func CompactToBig(compact uint32) *big.Int {
+	// Extract the mantissa, sign bit, and exponent.
+	mantissa := compact & 0x007fffff
+	isNegative := compact&0x00800000 != 0
+	exponent := uint(compact >> 24)
+
+	// Since the base for the exponent is 256, the exponent can be treated
+	// as the number of bytes to represent the full 256-bit number.  So,
+	// treat the exponent as the number of bytes and shift the mantissa
+	// right or left accordingly.  This is equivalent to:
+	// N = mantissa * 256^(exponent-3)
+	var bn *big.Int
+	if exponent <= 3 {
+		mantissa >>= 8 * (3 - exponent)
+		bn = big.NewInt(int64(mantissa))
+	} else {
+		bn = big.NewInt(int64(mantissa))
+		bn.Lsh(bn, 8*(exponent-3))
+	}
+
+	// Make it negative if the sign bit is set.
+	if isNegative {
+		bn = bn.Neg(bn)
+	}
+
+	return bn
+}
+
+// BigToCompact converts a whole number N to a compact representation using
+// an unsigned 32-bit number.  The compact representation only provides 23 bits
+// of precision, so values larger than (2^23 - 1) only encode the most
+// significant digits of the number.  See CompactToBig for details.
+func BigToCompact(n *big.Int) uint32 {
+	// No need to do any work if it's zero.
+	if n.Sign() == 0 {
+		return 0
+	}
+
+	// Since the base for the exponent is 256, the exponent can be treated
+	// as the number of bytes.  So, shift the number right or left
+	// accordingly.  This is equivalent to:
+	// mantissa = mantissa / 256^(exponent-3)
+	var mantissa uint32
+	exponent := uint(len(n.Bytes()))
+	if exponent <= 3 {
+		mantissa = uint32(n.Bits()[0])
+		mantissa <<= 8 * (3 - exponent)
+	} else {
+		// Use a copy to avoid modifying the caller's original number.
+		// The absolute value is used since the sign is encoded
+		// separately below.
+		tn := new(big.Int).Abs(n)
+		mantissa = uint32(tn.Rsh(tn, 8*(exponent-3)).Bits()[0])
+	}
+
+	// When the mantissa already has the sign bit set, the number is too
+	// large to fit into the available 23-bits, so divide the number by 256
+	// and increment the exponent accordingly.
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+
+	// Pack the exponent, sign bit, and mantissa into an unsigned 32-bit
+	// int and return it.
+	compact := uint32(exponent<<24) | mantissa
+	if n.Sign() < 0 {
+		compact |= 0x00800000
+	}
+	return compact
+}
+
+// CalcWork calculates a work value from difficulty bits.  Einsteinium
+// increases the difficulty for generating a block by decreasing the value
+// which the generated hash must be less than.  This difficulty target is
+// stored in each block header using a compact representation as described in
+// the documentation for CompactToBig.  The main chain is selected by choosing
+// the chain that has the most proof of work (highest difficulty).  Since a
+// lower target difficulty value equates to higher actual difficulty, the work
+// value which will be accumulated must be the inverse of the difficulty.  Also,
+// in order to avoid potential division by zero and really small floating point
+// numbers, the result adds 1 to the denominator and multiplies the numerator
+// by 2^256.
+func CalcWork(bits uint32) *big.Int {
+	// Return a work value of zero if the passed difficulty bits represent
+	// a negative number. Note this should not happen in practice with valid
+	// blocks, but an invalid block could trigger it.
+	difficultyNum := CompactToBig(bits)
+	if difficultyNum.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	// (1 << 256) / (difficultyNum + 1)
+	denominator := new(big.Int).Add(difficultyNum, bigOne)
+	return new(big.Int).Div(oneLsh256, denominator)
+}
+
+// CheckProofOfWork ensures the target difficulty encoded by bits is in the
+// valid range for the network and that the passed proof of work hash is less
+// than or equal to it.  ErrUnexpectedDifficulty is returned when the target is
+// not positive or exceeds PowLimit, and ErrHighHash is returned when the hash
+// does not satisfy the target.
+//
+// Note that the hash must be the proof of work hash of the block header (see
+// chainhash.ScryptPoWHash) rather than the block hash used to identify it.
+func (p *Params) CheckProofOfWork(hash *chainhash.Hash, bits uint32) error {
+	// The target difficulty must be larger than zero and must not exceed
+	// the maximum allowed by the network.
+	target := CompactToBig(bits)
+	if target.Sign() <= 0 || target.Cmp(p.PowLimit) > 0 {
+		return ErrUnexpectedDifficulty
+	}
+
+	// The block hash must be less than or equal to the claimed target.
+	if HashToBig(hash).Cmp(target) > 0 {
+		return ErrHighHash
+	}
+
+	return nil
+}
+
+// retargetTimespanLimits returns the minimum and maximum actual timespans, in
+// seconds, that a difficulty retarget will take into account.  Timespans
+// outside of this range are clamped to it which limits how much the difficulty
+// can change in a single retarget.
+//
+// RetargetAdjustmentFactorMin limits how quickly the difficulty may rise and
+// RetargetAdjustmentFactorMax limits how quickly it may fall.  Networks which
+// leave either of them unset fall back to the symmetric
+// RetargetAdjustmentFactor for that bound.
+func (p *Params) retargetTimespanLimits() (int64, int64) {
+	targetTimespan := int64(p.TargetTimespan / time.Second)
+
+	minFactor := p.RetargetAdjustmentFactorMin
+	if minFactor == 0 {
+		minFactor = p.RetargetAdjustmentFactor
+	}
+	maxFactor := p.RetargetAdjustmentFactorMax
+	if maxFactor == 0 {
+		maxFactor = p.RetargetAdjustmentFactor
+	}
+
+	return targetTimespan / minFactor, targetTimespan * maxFactor
+}
+
+// CalcNextRequiredDifficulty calculates the required difficulty for the block
+// following a retarget given the difficulty bits of the last block and the
+// actual time it took to mine the blocks of the retarget period.
+//
+// The actual timespan is limited as described by retargetTimespanLimits and
+// the resulting target never exceeds PowLimit.  The calculation mirrors the
+// reference implementation, including the one bit shift it applies to avoid
+// overflowing a 256-bit integer when the previous target is close to the proof
+// of work limit, so the result matches it exactly.
+func (p *Params) CalcNextRequiredDifficulty(lastBits uint32,
+	actualTimespan time.Duration) uint32 {
+
+	// Limit the amount of adjustment that can occur to the previous
+	// difficulty.
+	minTimespan, maxTimespan := p.retargetTimespanLimits()
+	adjustedTimespan := int64(actualTimespan / time.Second)
+	if adjustedTimespan < minTimespan {
+		adjustedTimespan = minTimespan
+	} else if adjustedTimespan > maxTimespan {
+		adjustedTimespan = maxTimespan
+	}
+
+	// Calculate new target difficulty as:
+	//  currentDifficulty * (adjustedTimespan / targetTimespan)
+	// The result uses integer division which means it will be slightly
+	// rounded down.  The reference implementation shifts the target right
+	// by one bit before the multiplication when it is within one bit of
+	// the size of the proof of work limit, and back afterwards, which drops
+	// the lowest bit.
+	oldTarget := CompactToBig(lastBits)
+	shift := oldTarget.BitLen() > p.PowLimit.BitLen()-1
+	newTarget := new(big.Int).Set(oldTarget)
+	if shift {
+		newTarget.Rsh(newTarget, 1)
+	}
+	targetTimespan := int64(p.TargetTimespan / time.Second)
+	newTarget.Mul(newTarget, big.NewInt(adjustedTimespan))
+	newTarget.Div(newTarget, big.NewInt(targetTimespan))
+	if shift {
+		newTarget.Lsh(newTarget, 1)
+	}
+
+	// Limit new value to the proof of work limit.
+	if newTarget.Cmp(p.PowLimit) > 0 {
+		newTarget.Set(p.PowLimit)
+	}
+
+	return BigToCompact(newTarget)
+}
+
+// HeaderProvider provides access to the block headers of the chain a
+// calculation is being performed against.  It allows the consensus helpers in
+// this package to be used without a full block chain implementation.
+type HeaderProvider interface {
+	// HeaderByHeight returns the header of the block at the passed height.
+	// An error must be returned when the header is not available.
+	HeaderByHeight(height int32) (*wire.BlockHeader, error)
+}
+
+// blocksPerRetarget returns the number of blocks between each difficulty
+// retarget.
+func (p *Params) blocksPerRetarget() int32 {
+	return int32(p.TargetTimespan / p.TargetTimePerBlock)
+}
+
+// findPrevTestNetDifficulty returns the difficulty of the previous block which
+// did not have the special testnet minimum difficulty rule applied.  It walks
+// backwards from the header at the passed height until it finds a block at a
+// retarget height or one whose bits differ from the proof of work limit.
+func (p *Params) findPrevTestNetDifficulty(headers HeaderProvider,
+	height int32) (uint32, error) {
+
+	blocksPerRetarget := p.blocksPerRetarget()
+	for {
+		header, err := headers.HeaderByHeight(height)
+		if err != nil {
+			return 0, err
+		}
+
+		if height == 0 || height%blocksPerRetarget == 0 ||
+			header.Bits != p.PowLimitBits {
+
+			return header.Bits, nil
+		}
+		height--
+	}
+}
+
+// NextRequiredDifficulty calculates the required difficulty for the block
+// that follows the block at lastHeight, given the timestamp of the new block,
+// using the difficulty algorithm active at that height.  See
+// DifficultyAlgorithmAt for details.
+func (p *Params) NextRequiredDifficulty(headers HeaderProvider,
+	lastHeight int32, newBlockTime time.Time) (uint32, error) {
+
+	algo := p.DifficultyAlgorithmAt(lastHeight + 1)
+	return algo.NextRequiredDifficulty(p, headers, lastHeight, newBlockTime)
+}
@@ -0,0 +1,574 @@
+// Copyright (c) 2014-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// hexToBig converts the passed hex string into a big integer and will panic if
+// there is an error.  This is only provided for the hard-coded constants so
+// errors in the source code can be detected.  It will only (and must only) be
+// called with hard-coded values.
+func hexToBig(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex in source file: " + s)
+	}
+	return n
+}
+
+// TestCompactToBig ensures compact encodings are converted to the expected
+// big integers and, where the encoding is canonical, back again.
+func TestCompactToBig(t *testing.T) {
+	tests := []struct {
+		name      string
+		compact   uint32
+		want      *big.Int
+		canonical uint32 // expected BigToCompact of want
+	}{
+		{"zero", 0x00000000, big.NewInt(0), 0},
+		{"exponent 0 mantissa truncated", 0x00123456, big.NewInt(0), 0},
+		{"exponent 1 mantissa truncated", 0x01003456, big.NewInt(0), 0},
+		{"exponent 2 mantissa truncated", 0x02000056, big.NewInt(0), 0},
+		{"exponent 3 zero mantissa", 0x03000000, big.NewInt(0), 0},
+		{"exponent 4 zero mantissa", 0x04000000, big.NewInt(0), 0},
+		{"negative exponent 0 truncated", 0x00923456, big.NewInt(0), 0},
+		{"negative exponent 1 truncated", 0x01803456, big.NewInt(0), 0},
+		{"negative exponent 2 truncated", 0x02800056, big.NewInt(0), 0},
+		{"negative zero exponent 3", 0x03800000, big.NewInt(0), 0},
+		{"negative zero exponent 4", 0x04800000, big.NewInt(0), 0},
+		{"exponent 1", 0x01123456, big.NewInt(0x12), 0x01120000},
+		{"negative exponent 1", 0x01fedcba, big.NewInt(-0x7e), 0x01fe0000},
+		{"exponent 2", 0x02123456, big.NewInt(0x1234), 0x02123400},
+		{"exponent 3", 0x03123456, big.NewInt(0x123456), 0x03123456},
+		{"exponent 4", 0x04123456, big.NewInt(0x12345600), 0x04123456},
+		{"negative exponent 4", 0x04923456, big.NewInt(-0x12345600), 0x04923456},
+		{"mantissa high bit", 0x05009234, big.NewInt(0x92340000), 0x05009234},
+		{
+			name:      "exponent 32",
+			compact:   0x20123456,
+			want:      hexToBig("1234560000000000000000000000000000000000000000000000000000000000"),
+			canonical: 0x20123456,
+		},
+		{
+			name:      "mainnet pow limit",
+			compact:   0x1e0fffff,
+			want:      hexToBig("00000fffff000000000000000000000000000000000000000000000000000000"),
+			canonical: 0x1e0fffff,
+		},
+		{
+			name:      "regtest pow limit",
+			compact:   0x207fffff,
+			want:      hexToBig("7fffff0000000000000000000000000000000000000000000000000000000000"),
+			canonical: 0x207fffff,
+		},
+		{
+			name:      "overflow exponent 35",
+			compact:   0x23000001,
+			want:      new(big.Int).Lsh(bigOne, 256),
+			canonical: 0x21010000,
+		},
+		{
+			name:      "overflow exponent 255",
+			compact:   0xff123456,
+			want:      new(big.Int).Lsh(big.NewInt(0x123456), 8*252),
+			canonical: 0xff123456,
+		},
+	}
+
+	for _, test := range tests {
+		n := CompactToBig(test.compact)
+		if n.Cmp(test.want) != 0 {
+			t.Errorf("%s: CompactToBig(%08x) = %x, want %x", test.name,
+				test.compact, n, test.want)
+			continue
+		}
+
+		compact := BigToCompact(n)
+		if compact != test.canonical {
+			t.Errorf("%s: BigToCompact(%x) = %08x, want %08x",
+				test.name, n, compact, test.canonical)
+		}
+	}
+}
+
+// TestBigToCompact ensures big integers are converted to the expected compact
+// encodings.
+func TestBigToCompact(t *testing.T) {
+	tests := []struct {
+		in   *big.Int
+		want uint32
+	}{
+		{big.NewInt(0), 0},
+		{big.NewInt(-1), 0x01810000},
+		{big.NewInt(0x80), 0x02008000},
+		{big.NewInt(-0x80), 0x02808000},
+		{big.NewInt(0x7fffff), 0x037fffff},
+		{big.NewInt(0x800000), 0x04008000},
+		{big.NewInt(-0x12345600), 0x04923456},
+		{mainPowLimit, 0x1e0fffff},
+		{regressionPowLimit, 0x207fffff},
+		{simNetPowLimit, 0x207fffff},
+		{testNet3PowLimit, 0x1d00ffff},
+	}
+
+	for i, test := range tests {
+		compact := BigToCompact(test.in)
+		if compact != test.want {
+			t.Errorf("#%d: BigToCompact(%x) = %08x, want %08x", i,
+				test.in, compact, test.want)
+		}
+	}
+}
+
+// TestCalcWork ensures the work calculated from difficulty bits is correct.
+func TestCalcWork(t *testing.T) {
+	tests := []struct {
+		bits uint32
+		want int64
+	}{
+		{0x1d00ffff, 4295032833},
+		{0x1e0fffff, 1048577},
+		{0x1e0ffff0, 1048592},
+		{0x1b0404cb, 70040908352512},
+		{0x207fffff, 2},
+		{0x00000000, 0},
+		{0x01fedcba, 0},
+		{0x04923456, 0},
+		{0x03800000, 0},
+		{0x23000001, 0},
+	}
+
+	for i, test := range tests {
+		work := CalcWork(test.bits)
+		if work.Cmp(big.NewInt(test.want)) != 0 {
+			t.Errorf("#%d: CalcWork(%08x) = %v, want %v", i, test.bits,
+				work, test.want)
+		}
+	}
+}
+
+// TestHashToBig ensures hashes are interpreted as little-endian numbers.
+func TestHashToBig(t *testing.T) {
+	hashStr := "0000000110c8357966576df46f3b802ca897deb7ad18b12f1c24ecff6386ebd9"
+	hash, err := chainhash.NewHashFromStr(hashStr)
+	if err != nil {
+		t.Fatalf("NewHashFromStr: unexpected error: %v", err)
+	}
+
+	want := hexToBig(hashStr)
+	if got := HashToBig(hash); got.Cmp(want) != 0 {
+		t.Errorf("HashToBig = %x, want %x", got, want)
+	}
+
+	// Ensure the passed hash was not modified.
+	if hash.String() != hashStr {
+		t.Errorf("HashToBig modified hash - got %v, want %v", hash,
+			hashStr)
+	}
+}
+
+// TestCheckProofOfWork ensures proof of work checks enforce both the network
+// limit and the target encoded in the bits.
+func TestCheckProofOfWork(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *Params
+		hash   string
+		bits   uint32
+		err    error
+	}{
+		{
+			name:   "hash below target",
+			params: &MainNetParams,
+			hash:   "0000000110c8357966576df46f3b802ca897deb7ad18b12f1c24ecff6386ebd9",
+			bits:   0x1d018ea7,
+			err:    nil,
+		},
+		{
+			name:   "hash equal to target",
+			params: &MainNetParams,
+			hash:   "00000fffff000000000000000000000000000000000000000000000000000000",
+			bits:   0x1e0fffff,
+			err:    nil,
+		},
+		{
+			name:   "hash one above target",
+			params: &MainNetParams,
+			hash:   "00000fffff000000000000000000000000000000000000000000000000000001",
+			bits:   0x1e0fffff,
+			err:    ErrHighHash,
+		},
+		{
+			name:   "hash above target",
+			params: &MainNetParams,
+			hash:   "0000000110c8357966576df46f3b802ca897deb7ad18b12f1c24ecff6386ebd9",
+			bits:   0x1d00ffff,
+			err:    ErrHighHash,
+		},
+		{
+			name:   "target above pow limit",
+			params: &MainNetParams,
+			hash:   "0000000000000000000000000000000000000000000000000000000000000001",
+			bits:   0x1e100000,
+			err:    ErrUnexpectedDifficulty,
+		},
+		{
+			name:   "target above pow limit on regtest",
+			params: &RegressionNetParams,
+			hash:   "0000000000000000000000000000000000000000000000000000000000000001",
+			bits:   0x20800000,
+			err:    ErrUnexpectedDifficulty,
+		},
+		{
+			name:   "regtest pow limit",
+			params: &RegressionNetParams,
+			hash:   "7ffffe0000000000000000000000000000000000000000000000000000000000",
+			bits:   0x207fffff,
+			err:    nil,
+		},
+		{
+			name:   "zero target",
+			params: &MainNetParams,
+			hash:   "0000000000000000000000000000000000000000000000000000000000000000",
+			bits:   0x03000000,
+			err:    ErrUnexpectedDifficulty,
+		},
+		{
+			name:   "negative target",
+			params: &MainNetParams,
+			hash:   "0000000000000000000000000000000000000000000000000000000000000000",
+			bits:   0x1d80ffff,
+			err:    ErrUnexpectedDifficulty,
+		},
+		{
+			name:   "overflowing target",
+			params: &RegressionNetParams,
+			hash:   "0000000000000000000000000000000000000000000000000000000000000000",
+			bits:   0xff123456,
+			err:    ErrUnexpectedDifficulty,
+		},
+	}
+
+	for _, test := range tests {
+		hash, err := chainhash.NewHashFromStr(test.hash)
+		if err != nil {
+			t.Errorf("%s: NewHashFromStr: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+
+		err = test.params.CheckProofOfWork(hash, test.bits)
+		if err != test.err {
+			t.Errorf("%s: CheckProofOfWork = %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCalcNextRequiredDifficulty ensures the difficulty retarget applies the
+// adjustment limits of each network exactly as the reference implementation.
+func TestCalcNextRequiredDifficulty(t *testing.T) {
+	// bitcoinParams mirrors the retarget parameters of the Bitcoin main
+	// network which allows the calculation to be checked against the real
+	// chain data it produced.
+	bitcoinParams := Params{
+		PowLimit:                 testNet3PowLimit,
+		TargetTimespan:           time.Hour * 24 * 14,
+		RetargetAdjustmentFactor: 4,
+	}
+
+	tests := []struct {
+		name     string
+		params   *Params
+		lastBits uint32
+		timespan time.Duration
+		want     uint32
+	}{
+		{
+			// Blocks 30240 to 32255 of the Bitcoin main chain.
+			name:     "bitcoin block 32256",
+			params:   &bitcoinParams,
+			lastBits: 0x1d00ffff,
+			timespan: (1262152739 - 1261130161) * time.Second,
+			want:     0x1d00d86a,
+		},
+		{
+			// Blocks 0 to 2015 of the Bitcoin main chain.
+			name:     "bitcoin block 2016 at pow limit",
+			params:   &bitcoinParams,
+			lastBits: 0x1d00ffff,
+			timespan: (1233061996 - 1231006505) * time.Second,
+			want:     0x1d00ffff,
+		},
+		{
+			// Blocks 68544 to 70559 of the Bitcoin main chain.
+			name:     "bitcoin lower limit of actual timespan",
+			params:   &bitcoinParams,
+			lastBits: 0x1c05a3f4,
+			timespan: (1279297671 - 1279008237) * time.Second,
+			want:     0x1c0168fd,
+		},
+		{
+			// Blocks 46368 to 48383 of the Bitcoin main chain.
+			name:     "bitcoin upper limit of actual timespan",
+			params:   &bitcoinParams,
+			lastBits: 0x1c387f6f,
+			timespan: (1269211443 - 1263163443) * time.Second,
+			want:     0x1d00e1fd,
+		},
+		{
+			name:     "mainnet on target",
+			params:   &MainNetParams,
+			lastBits: 0x1c0ac141,
+			timespan: 60 * time.Second,
+			want:     0x1c0ac141,
+		},
+		{
+			name:     "mainnet faster than target",
+			params:   &MainNetParams,
+			lastBits: 0x1d1c6ab5,
+			timespan: 45 * time.Second,
+			want:     0x1d155007,
+		},
+		{
+			name:     "mainnet slower than target",
+			params:   &MainNetParams,
+			lastBits: 0x1c0ac141,
+			timespan: 90 * time.Second,
+			want:     0x1c1021e1,
+		},
+		{
+			name:     "mainnet difficulty rises at most 4x",
+			params:   &MainNetParams,
+			lastBits: 0x1c0ac141,
+			timespan: time.Second,
+			want:     0x1c02b050,
+		},
+		{
+			name:     "mainnet at min adjustment factor",
+			params:   &MainNetParams,
+			lastBits: 0x1c0ac141,
+			timespan: 15 * time.Second,
+			want:     0x1c02b050,
+		},
+		{
+			name:     "mainnet difficulty falls at most 2x",
+			params:   &MainNetParams,
+			lastBits: 0x1c0ac141,
+			timespan: 600 * time.Second,
+			want:     0x1c158282,
+		},
+		{
+			name:     "mainnet at max adjustment factor",
+			params:   &MainNetParams,
+			lastBits: 0x1c0ac141,
+			timespan: 120 * time.Second,
+			want:     0x1c158282,
+		},
+		{
+			name:     "mainnet close to pow limit",
+			params:   &MainNetParams,
+			lastBits: 0x1e07ffff,
+			timespan: 600 * time.Second,
+			want:     0x1e0ffffe,
+		},
+		{
+			name:     "mainnet clamped to pow limit",
+			params:   &MainNetParams,
+			lastBits: 0x1e0fffff,
+			timespan: 120 * time.Second,
+			want:     0x1e0fffff,
+		},
+		{
+			name:     "regtest symmetric fallback at pow limit",
+			params:   &RegressionNetParams,
+			lastBits: 0x207fffff,
+			timespan: RegressionNetParams.TargetTimespan,
+			want:     0x207fffff,
+		},
+		{
+			name:     "regtest symmetric fallback lower limit",
+			params:   &RegressionNetParams,
+			lastBits: 0x207fffff,
+			timespan: time.Second,
+			want:     0x201fffff,
+		},
+		{
+			name:     "regtest symmetric fallback upper limit",
+			params:   &RegressionNetParams,
+			lastBits: 0x1f3fffff,
+			timespan: RegressionNetParams.TargetTimespan * 10,
+			want:     0x2000ffff,
+		},
+		{
+			name:     "regtest faster than target",
+			params:   &RegressionNetParams,
+			lastBits: 0x1f3fffff,
+			timespan: 100 * time.Second,
+			want:     0x1f0fffff,
+		},
+	}
+
+	for _, test := range tests {
+		bits := test.params.CalcNextRequiredDifficulty(test.lastBits,
+			test.timespan)
+		if bits != test.want {
+			t.Errorf("%s: CalcNextRequiredDifficulty = %08x, want %08x",
+				test.name, bits, test.want)
+		}
+	}
+}
+
+// mockHeaders implements the HeaderProvider interface for a chain whose
+// headers are indexed by height.
+type mockHeaders []wire.BlockHeader
+
+// HeaderByHeight returns the header at the passed height.
+//
+// This is part of the HeaderProvider interface.
+func (m mockHeaders) HeaderByHeight(height int32) (*wire.BlockHeader, error) {
+	if height < 0 || int(height) >= len(m) {
+		return nil, fmt.Errorf("no header at height %d", height)
+	}
+	return &m[height], nil
+}
+
+// TestNextRequiredDifficulty ensures the difficulty required for the next
+// block honours the retarget interval and the special minimum difficulty rules
+// for test networks.
+func TestNextRequiredDifficulty(t *testing.T) {
+	// minDiffParams defines a network which retargets every four blocks
+	// and allows minimum difficulty blocks after five minutes without a
+	// block.
+	minDiffParams := Params{
+		PowLimit:                 regressionPowLimit,
+		PowLimitBits:             0x207fffff,
+		TargetTimespan:           time.Second * 600,
+		TargetTimePerBlock:       time.Second * 150,
+		RetargetAdjustmentFactor: 4,
+		ReduceMinDifficulty:      true,
+		MinDiffReductionTime:     time.Second * 300,
+	}
+	noMinDiffParams := minDiffParams
+	noMinDiffParams.ReduceMinDifficulty = false
+
+	header := func(timestamp int64, bits uint32) wire.BlockHeader {
+		return wire.BlockHeader{
+			Timestamp: time.Unix(timestamp, 0),
+			Bits:      bits,
+		}
+	}
+	headers := mockHeaders{
+		header(0, 0x207fffff),    // 0 genesis
+		header(150, 0x1f3fffff),  // 1
+		header(300, 0x207fffff),  // 2 min difficulty
+		header(450, 0x1f00ffff),  // 3
+		header(600, 0x207fffff),  // 4 retarget at the pow limit
+		header(750, 0x207fffff),  // 5 min difficulty
+		header(900, 0x207fffff),  // 6 min difficulty
+		header(1500, 0x1f00ffff), // 7
+	}
+
+	tests := []struct {
+		name       string
+		params     *Params
+		lastHeight int32
+		blockTime  int64
+		want       uint32
+	}{
+		{
+			name:       "genesis",
+			params:     &minDiffParams,
+			lastHeight: -1,
+			blockTime:  0,
+			want:       0x207fffff,
+		},
+		{
+			name:       "min difficulty after reduction time",
+			params:     &minDiffParams,
+			lastHeight: 1,
+			blockTime:  451,
+			want:       0x207fffff,
+		},
+		{
+			name:       "exactly at reduction time",
+			params:     &minDiffParams,
+			lastHeight: 1,
+			blockTime:  450,
+			want:       0x1f3fffff,
+		},
+		{
+			name:       "walk back over min difficulty block",
+			params:     &minDiffParams,
+			lastHeight: 2,
+			blockTime:  400,
+			want:       0x1f3fffff,
+		},
+		{
+			name:       "walk back stops at retarget height",
+			params:     &minDiffParams,
+			lastHeight: 6,
+			blockTime:  1000,
+			want:       0x207fffff,
+		},
+		{
+			name:       "no min difficulty carries last bits",
+			params:     &noMinDiffParams,
+			lastHeight: 1,
+			blockTime:  10000,
+			want:       0x1f3fffff,
+		},
+		{
+			name:       "no min difficulty does not walk back",
+			params:     &noMinDiffParams,
+			lastHeight: 2,
+			blockTime:  400,
+			want:       0x207fffff,
+		},
+		{
+			name:       "first retarget",
+			params:     &minDiffParams,
+			lastHeight: 3,
+			blockTime:  10000,
+			want:       0x1f00bfff,
+		},
+		{
+			name:       "second retarget",
+			params:     &minDiffParams,
+			lastHeight: 7,
+			blockTime:  10000,
+			want:       0x1f01bffe,
+		},
+	}
+
+	for _, test := range tests {
+		bits, err := test.params.NextRequiredDifficulty(headers,
+			test.lastHeight, time.Unix(test.blockTime, 0))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if bits != test.want {
+			t.Errorf("%s: NextRequiredDifficulty = %08x, want %08x",
+				test.name, bits, test.want)
+		}
+	}
+
+	// Ensure errors from the header provider are returned.
+	_, err := minDiffParams.NextRequiredDifficulty(headers,
+		int32(len(headers)), time.Unix(10000, 0))
+	if err == nil {
+		t.Error("NextRequiredDifficulty: did not receive expected error " +
+			"for missing header")
+	}
+}
@@ -0,0 +1,403 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrDifficultyAlgorithmsNotAscending describes an error where the
+	// activation heights of the difficulty algorithms of a network are not
+	// strictly ascending.
+	ErrDifficultyAlgorithmsNotAscending = errors.New("difficulty algorithm " +
+		"activation heights are not strictly ascending")
+
+	// ErrInvalidDifficultyAlgorithm describes an error where a difficulty
+	// algorithm of a network is missing or is configured such that it can
+	// not calculate a difficulty, such as averaging over no blocks.
+	ErrInvalidDifficultyAlgorithm = errors.New("invalid difficulty " +
+		"algorithm")
+)
+
+// DifficultyAlgorithm defines an algorithm which determines the difficulty
+// required for the next block of a chain.  Networks declare which algorithm
+// they use, and from which height, through Params.DifficultyAlgorithms.
+type DifficultyAlgorithm interface {
+	// Name returns a human-readable identifier for the algorithm.
+	Name() string
+
+	// NextRequiredDifficulty calculates the required difficulty, in
+	// compact form, for the block that follows the block at lastHeight
+	// given the timestamp of the new block.  A lastHeight below zero means
+	// there is no previous block.
+	NextRequiredDifficulty(p *Params, headers HeaderProvider,
+		lastHeight int32, newBlockTime time.Time) (uint32, error)
+}
+
+// DifficultyActivation defines the height from which a difficulty algorithm
+// is used to calculate the required difficulty of blocks.
+type DifficultyActivation struct {
+	// Height is the height of the first block whose difficulty is
+	// calculated by the algorithm.
+	Height int32
+
+	// Algorithm is the difficulty algorithm to use.
+	Algorithm DifficultyAlgorithm
+}
+
+// DifficultyAlgorithmAt returns the difficulty algorithm used to calculate the
+// required difficulty of the block at the passed height.  It is the algorithm
+// of the last entry in DifficultyAlgorithms with an activation height at or
+// below the passed height.  The classic interval retarget is returned when no
+// entry is active.
+func (p *Params) DifficultyAlgorithmAt(height int32) DifficultyAlgorithm {
+	// Find the first activation which is above the height.  The one right
+	// before it, if any, is the active algorithm.
+	algos := p.DifficultyAlgorithms
+	i := sort.Search(len(algos), func(i int) bool {
+		return algos[i].Height > height
+	})
+	if i == 0 {
+		return ClassicRetarget{}
+	}
+	return algos[i-1].Algorithm
+}
+
+// validateDifficultyAlgorithms returns an error when the activation heights of
+// the difficulty algorithms of the network are not strictly ascending, which
+// DifficultyAlgorithmAt relies on, or when an algorithm is misconfigured.
+//
+// The classic retarget, which is also used before the first entry, divides by
+// the target timespan and the target time per block, so whenever it can be
+// selected both must be at least a second and the timespan must cover at least
+// one block.
+func (p *Params) validateDifficultyAlgorithms() error {
+	algos := p.DifficultyAlgorithms
+	if len(algos) == 0 || algos[0].Height > 0 {
+		if err := p.validateClassicRetarget(); err != nil {
+			return err
+		}
+	}
+	for i, algo := range algos {
+		if i > 0 && algo.Height <= algos[i-1].Height {
+			return ErrDifficultyAlgorithmsNotAscending
+		}
+
+		switch a := algo.Algorithm.(type) {
+		case nil:
+			return ErrInvalidDifficultyAlgorithm
+
+		case ClassicRetarget:
+			if err := p.validateClassicRetarget(); err != nil {
+				return err
+			}
+
+		case DarkGravityWave:
+			if a.PastBlocks <= 0 || p.TargetTimePerBlock < time.Second {
+				return ErrInvalidDifficultyAlgorithm
+			}
+
+		case LWMA:
+			if a.Window <= 0 || p.TargetTimePerBlock < time.Second {
+				return ErrInvalidDifficultyAlgorithm
+			}
+		}
+	}
+	return nil
+}
+
+// validateClassicRetarget returns an error when the target timespan and target
+// time per block of the network do not yield a positive number of blocks per
+// retarget.
+func (p *Params) validateClassicRetarget() error {
+	if p.TargetTimePerBlock < time.Second ||
+		p.TargetTimespan < p.TargetTimePerBlock {
+
+		return ErrInvalidDifficultyAlgorithm
+	}
+	return nil
+}
+
+// ClassicRetarget is the interval based difficulty retarget inherited from
+// Bitcoin and Litecoin.  The difficulty only changes every TargetTimespan /
+// TargetTimePerBlock blocks, limited by the retarget adjustment factors of the
+// network.
+type ClassicRetarget struct{}
+
+// Ensure ClassicRetarget implements the DifficultyAlgorithm interface.
+var _ DifficultyAlgorithm = ClassicRetarget{}
+
+// Name returns the name of the algorithm.
+//
+// This is part of the DifficultyAlgorithm interface.
+func (ClassicRetarget) Name() string {
+	return "classic"
+}
+
+// NextRequiredDifficulty calculates the required difficulty for the block
+// that follows the block at lastHeight, given the timestamp of the new block.
+//
+// Outside of retarget heights, the difficulty of the last block carries over,
+// unless the network allows minimum difficulty blocks (ReduceMinDifficulty).
+// In that case, a block whose timestamp is more than MinDiffReductionTime after
+// the last block only requires the proof of work limit, and otherwise requires
+// the difficulty of the most recent block that was not mined under that rule.
+//
+// At retarget heights the difficulty is recalculated with
+// CalcNextRequiredDifficulty over the actual timespan of the retarget period.
+// Like the reference implementation, the period reaches back one block further
+// than the retarget interval except for the very first retarget.
+//
+// A lastHeight below zero means there is no previous block, in which case the
+// proof of work limit is returned.
+//
+// This is part of the DifficultyAlgorithm interface.
+func (ClassicRetarget) NextRequiredDifficulty(p *Params,
+	headers HeaderProvider, lastHeight int32,
+	newBlockTime time.Time) (uint32, error) {
+
+	// Genesis block.
+	if lastHeight < 0 {
+		return p.PowLimitBits, nil
+	}
+
+	lastHeader, err := headers.HeaderByHeight(lastHeight)
+	if err != nil {
+		return 0, err
+	}
+
+	// Return the previous block's difficulty requirements if this block
+	// is not at a difficulty retarget interval.
+	blocksPerRetarget := p.blocksPerRetarget()
+	if (lastHeight+1)%blocksPerRetarget != 0 {
+		// For networks that support it, allow special reduction of the
+		// required difficulty once too much time has elapsed without
+		// mining a block.
+		if p.ReduceMinDifficulty {
+			// Return minimum difficulty when more than the desired
+			// amount of time has elapsed without mining a block.
+			allowMinTime := lastHeader.Timestamp.Add(p.MinDiffReductionTime)
+			if newBlockTime.After(allowMinTime) {
+				return p.PowLimitBits, nil
+			}
+
+			// The block was mined within the desired timeframe, so
+			// return the difficulty for the last block which did
+			// not have the special minimum difficulty rule applied.
+			return p.findPrevTestNetDifficulty(headers, lastHeight)
+		}
+
+		// For the main network (or any unrecognized networks), simply
+		// return the previous block's difficulty requirements.
+		return lastHeader.Bits, nil
+	}
+
+	// Go back by what we want to be the target timespan worth of blocks.
+	// This is one block more than the retarget interval, except for the
+	// first retarget where only the blocks after the genesis block are
+	// available.
+	blocksToGoBack := blocksPerRetarget
+	if lastHeight+1 == blocksPerRetarget {
+		blocksToGoBack = blocksPerRetarget - 1
+	}
+	firstHeader, err := headers.HeaderByHeight(lastHeight - blocksToGoBack)
+	if err != nil {
+		return 0, err
+	}
+
+	actualTimespan := lastHeader.Timestamp.Sub(firstHeader.Timestamp)
+	return p.CalcNextRequiredDifficulty(lastHeader.Bits, actualTimespan), nil
+}
+
+// DarkGravityWave is version 3 of the Dark Gravity Wave per-block difficulty
+// algorithm as introduced by Dash.  It retargets every block based on a
+// weighted average of the targets of the last PastBlocks blocks and the time
+// it took to mine them, with the adjustment limited to a factor of three.
+type DarkGravityWave struct {
+	// PastBlocks is the number of blocks the algorithm averages over.  It
+	// is 24 in the reference implementation.
+	PastBlocks int32
+}
+
+// Ensure DarkGravityWave implements the DifficultyAlgorithm interface.
+var _ DifficultyAlgorithm = DarkGravityWave{}
+
+// Name returns the name of the algorithm.
+//
+// This is part of the DifficultyAlgorithm interface.
+func (DarkGravityWave) Name() string {
+	return "dgw3"
+}
+
+// NextRequiredDifficulty calculates the required difficulty for the block that
+// follows the block at lastHeight as defined by Dark Gravity Wave v3.
+//
+// The proof of work limit is required until more than PastBlocks blocks exist.
+// On networks which allow minimum difficulty blocks, a block more than two
+// hours after the last block only requires the proof of work limit and a block
+// more than four times TargetTimePerBlock after it requires ten times the
+// target of the last block.
+//
+// This is part of the DifficultyAlgorithm interface.
+func (d DarkGravityWave) NextRequiredDifficulty(p *Params,
+	headers HeaderProvider, lastHeight int32,
+	newBlockTime time.Time) (uint32, error) {
+
+	// Make sure there are at least PastBlocks + 1 blocks.
+	if lastHeight < d.PastBlocks {
+		return p.PowLimitBits, nil
+	}
+
+	lastHeader, err := headers.HeaderByHeight(lastHeight)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.ReduceMinDifficulty {
+		// The last block is more than two hours old.
+		if newBlockTime.After(lastHeader.Timestamp.Add(2 * time.Hour)) {
+			return p.PowLimitBits, nil
+		}
+
+		// The last block is more than four target spacings old.
+		if newBlockTime.After(lastHeader.Timestamp.Add(4 * p.TargetTimePerBlock)) {
+			newTarget := CompactToBig(lastHeader.Bits)
+			newTarget.Mul(newTarget, big.NewInt(10))
+			if newTarget.Cmp(p.PowLimit) > 0 {
+				newTarget.Set(p.PowLimit)
+			}
+			return BigToCompact(newTarget), nil
+		}
+	}
+
+	// Calculate the running average of the past targets.  Note that, as
+	// in the reference implementation, every target after the first is
+	// weighted by its position rather than producing a true average.
+	pastTargetAvg := new(big.Int)
+	firstHeader := lastHeader
+	for count := int64(1); count <= int64(d.PastBlocks); count++ {
+		if count > 1 {
+			firstHeader, err = headers.HeaderByHeight(lastHeight -
+				int32(count) + 1)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		target := CompactToBig(firstHeader.Bits)
+		if count == 1 {
+			pastTargetAvg.Set(target)
+			continue
+		}
+		pastTargetAvg.Mul(pastTargetAvg, big.NewInt(count))
+		pastTargetAvg.Add(pastTargetAvg, target)
+		pastTargetAvg.Div(pastTargetAvg, big.NewInt(count+1))
+	}
+
+	// Limit the actual timespan, which only covers PastBlocks - 1 block
+	// intervals, to a third and three times the target timespan.
+	targetTimespan := int64(d.PastBlocks) *
+		int64(p.TargetTimePerBlock/time.Second)
+	actualTimespan := lastHeader.Timestamp.Unix() -
+		firstHeader.Timestamp.Unix()
+	if actualTimespan < targetTimespan/3 {
+		actualTimespan = targetTimespan / 3
+	}
+	if actualTimespan > targetTimespan*3 {
+		actualTimespan = targetTimespan * 3
+	}
+
+	// Retarget.
+	newTarget := pastTargetAvg.Mul(pastTargetAvg, big.NewInt(actualTimespan))
+	newTarget.Div(newTarget, big.NewInt(targetTimespan))
+	if newTarget.Cmp(p.PowLimit) > 0 {
+		newTarget.Set(p.PowLimit)
+	}
+
+	return BigToCompact(newTarget), nil
+}
+
+// LWMA is the linearly weighted moving average per-block difficulty algorithm
+// (LWMA-3) by zawy12.  It retargets every block based on the targets of the
+// last Window blocks and their solve times, weighting recent solve times more
+// heavily.
+type LWMA struct {
+	// Window is the number of blocks the algorithm averages over.
+	Window int32
+}
+
+// Ensure LWMA implements the DifficultyAlgorithm interface.
+var _ DifficultyAlgorithm = LWMA{}
+
+// Name returns the name of the algorithm.
+//
+// This is part of the DifficultyAlgorithm interface.
+func (LWMA) Name() string {
+	return "lwma"
+}
+
+// NextRequiredDifficulty calculates the required difficulty for the block that
+// follows the block at lastHeight as defined by LWMA-3.
+//
+// The proof of work limit is required until at least Window blocks follow the
+// genesis block.  Out of order timestamps are treated as one second after the
+// previous block and solve times are limited to six times TargetTimePerBlock.
+//
+// This is part of the DifficultyAlgorithm interface.
+func (l LWMA) NextRequiredDifficulty(p *Params, headers HeaderProvider,
+	lastHeight int32, newBlockTime time.Time) (uint32, error) {
+
+	if lastHeight < l.Window {
+		return p.PowLimitBits, nil
+	}
+
+	n := int64(l.Window)
+	t := int64(p.TargetTimePerBlock / time.Second)
+	k := n * (n + 1) * t / 2
+
+	header, err := headers.HeaderByHeight(lastHeight - l.Window)
+	if err != nil {
+		return 0, err
+	}
+	prevTimestamp := header.Timestamp.Unix()
+
+	// Loop through the most recent blocks summing their weighted solve
+	// times and their targets.
+	var weightedSolveTimes int64
+	sumTargets := new(big.Int)
+	divisor := big.NewInt(k * n)
+	for i := int64(1); i <= n; i++ {
+		header, err := headers.HeaderByHeight(lastHeight - l.Window +
+			int32(i))
+		if err != nil {
+			return 0, err
+		}
+
+		timestamp := header.Timestamp.Unix()
+		if timestamp <= prevTimestamp {
+			timestamp = prevTimestamp + 1
+		}
+		solveTime := timestamp - prevTimestamp
+		if solveTime > 6*t {
+			solveTime = 6 * t
+		}
+		prevTimestamp = timestamp
+		weightedSolveTimes += solveTime * i
+
+		target := CompactToBig(header.Bits)
+		sumTargets.Add(sumTargets, target.Div(target, divisor))
+	}
+
+	newTarget := sumTargets.Mul(sumTargets, big.NewInt(weightedSolveTimes))
+	if newTarget.Cmp(p.PowLimit) > 0 {
+		newTarget.Set(p.PowLimit)
+	}
+
+	return BigToCompact(newTarget), nil
+}
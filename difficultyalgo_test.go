@@ -0,0 +1,417 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// perBlockTestChain returns a chain of 40 headers with uneven solve times,
+// including one out of order timestamp, and varying difficulty bits which is
+// used to exercise the per-block difficulty algorithms.
+func perBlockTestChain() mockHeaders {
+	solveTimes := []int64{30, 50, 45, 20, 60, 10, 80, 35, -20, 100}
+	bits := []uint32{0x1e01ffff, 0x1e00ffff, 0x1d7fffff, 0x1e03ffff,
+		0x1e02fff0}
+
+	headers := make(mockHeaders, 40)
+	timestamp := int64(1500000000)
+	for i := range headers {
+		if i > 0 {
+			timestamp += solveTimes[i%len(solveTimes)]
+		}
+		headers[i] = wire.BlockHeader{
+			Timestamp: time.Unix(timestamp, 0),
+			Bits:      bits[i%len(bits)],
+		}
+	}
+	return headers
+}
+
+// TestDifficultyAlgorithmAt ensures the active difficulty algorithm is
+// selected by activation height.
+func TestDifficultyAlgorithmAt(t *testing.T) {
+	params := Params{
+		DifficultyAlgorithms: []DifficultyActivation{
+			{Height: 10, Algorithm: ClassicRetarget{}},
+			{Height: 100, Algorithm: DarkGravityWave{PastBlocks: 24}},
+			{Height: 200, Algorithm: LWMA{Window: 45}},
+		},
+	}
+
+	tests := []struct {
+		height int32
+		want   string
+	}{
+		{0, "classic"},
+		{9, "classic"},
+		{10, "classic"},
+		{99, "classic"},
+		{100, "dgw3"},
+		{199, "dgw3"},
+		{200, "lwma"},
+		{1000000, "lwma"},
+	}
+
+	for _, test := range tests {
+		algo := params.DifficultyAlgorithmAt(test.height)
+		if algo.Name() != test.want {
+			t.Errorf("DifficultyAlgorithmAt(%d) = %v, want %v",
+				test.height, algo.Name(), test.want)
+		}
+	}
+
+	// Ensure all of the default networks declare an algorithm for the
+	// genesis block.
+	for _, params := range []*Params{&MainNetParams, &TestNet4Params,
+		&RegressionNetParams, &SimNetParams} {
+
+		if len(params.DifficultyAlgorithms) == 0 ||
+			params.DifficultyAlgorithms[0].Height != 0 {
+
+			t.Errorf("%s: no difficulty algorithm declared from "+
+				"genesis", params.Name)
+		}
+	}
+}
+
+// TestPerBlockDifficultyAlgorithms ensures the per-block difficulty algorithms
+// produce the same results as the reference implementations.
+func TestPerBlockDifficultyAlgorithms(t *testing.T) {
+	headers := perBlockTestChain()
+	minDiffParams := MainNetParams
+	minDiffParams.ReduceMinDifficulty = true
+
+	tests := []struct {
+		name       string
+		params     *Params
+		algo       DifficultyAlgorithm
+		lastHeight int32
+		delay      int64 // seconds between last block and new block
+		want       uint32
+	}{
+		{
+			name:       "dgw not enough blocks",
+			params:     &MainNetParams,
+			algo:       DarkGravityWave{PastBlocks: 24},
+			lastHeight: 23,
+			delay:      60,
+			want:       0x1e0fffff,
+		},
+		{
+			name:       "dgw first retarget",
+			params:     &MainNetParams,
+			algo:       DarkGravityWave{PastBlocks: 24},
+			lastHeight: 24,
+			delay:      60,
+			want:       0x1e016782,
+		},
+		{
+			name:       "dgw height 31",
+			params:     &MainNetParams,
+			algo:       DarkGravityWave{PastBlocks: 24},
+			lastHeight: 30,
+			delay:      60,
+			want:       0x1e0161cd,
+		},
+		{
+			name:       "dgw height 40",
+			params:     &MainNetParams,
+			algo:       DarkGravityWave{PastBlocks: 24},
+			lastHeight: 39,
+			delay:      60,
+			want:       0x1e0163b4,
+		},
+		{
+			name:       "dgw ignores delay without min difficulty",
+			params:     &MainNetParams,
+			algo:       DarkGravityWave{PastBlocks: 24},
+			lastHeight: 30,
+			delay:      10000,
+			want:       0x1e0161cd,
+		},
+		{
+			name:       "dgw min difficulty on time",
+			params:     &minDiffParams,
+			algo:       DarkGravityWave{PastBlocks: 24},
+			lastHeight: 32,
+			delay:      240,
+			want:       0x1e014945,
+		},
+		{
+			name:       "dgw min difficulty after four spacings",
+			params:     &minDiffParams,
+			algo:       DarkGravityWave{PastBlocks: 24},
+			lastHeight: 32,
+			delay:      241,
+			want:       0x1e04ffff,
+		},
+		{
+			name:       "dgw min difficulty clamped to pow limit",
+			params:     &minDiffParams,
+			algo:       DarkGravityWave{PastBlocks: 24},
+			lastHeight: 30,
+			delay:      241,
+			want:       0x1e0fffff,
+		},
+		{
+			name:       "dgw min difficulty after two hours",
+			params:     &minDiffParams,
+			algo:       DarkGravityWave{PastBlocks: 24},
+			lastHeight: 32,
+			delay:      7201,
+			want:       0x1e0fffff,
+		},
+		{
+			name:       "lwma not enough blocks",
+			params:     &MainNetParams,
+			algo:       LWMA{Window: 10},
+			lastHeight: 5,
+			delay:      60,
+			want:       0x1e0fffff,
+		},
+		{
+			name:       "lwma first retarget",
+			params:     &MainNetParams,
+			algo:       LWMA{Window: 10},
+			lastHeight: 10,
+			delay:      60,
+			want:       0x1e016bed,
+		},
+		{
+			name:       "lwma height 21",
+			params:     &MainNetParams,
+			algo:       LWMA{Window: 10},
+			lastHeight: 20,
+			delay:      60,
+			want:       0x1e016bed,
+		},
+		{
+			name:       "lwma height 40",
+			params:     &MainNetParams,
+			algo:       LWMA{Window: 10},
+			lastHeight: 39,
+			delay:      60,
+			want:       0x1e017dd9,
+		},
+	}
+
+	for _, test := range tests {
+		lastTime := headers[test.lastHeight].Timestamp
+		newBlockTime := lastTime.Add(time.Duration(test.delay) * time.Second)
+		bits, err := test.algo.NextRequiredDifficulty(test.params,
+			headers, test.lastHeight, newBlockTime)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if bits != test.want {
+			t.Errorf("%s: NextRequiredDifficulty = %08x, want %08x",
+				test.name, bits, test.want)
+		}
+	}
+}
+
+// TestNextRequiredDifficultySwitch ensures the required difficulty is
+// calculated with the algorithm active at the height of the new block.
+func TestNextRequiredDifficultySwitch(t *testing.T) {
+	headers := perBlockTestChain()
+	params := MainNetParams
+	params.DifficultyAlgorithms = []DifficultyActivation{
+		{Height: 0, Algorithm: ClassicRetarget{}},
+		{Height: 31, Algorithm: DarkGravityWave{PastBlocks: 24}},
+	}
+
+	tests := []struct {
+		lastHeight int32
+		want       uint32
+	}{
+		// The classic retarget runs every block on the main network,
+		// so the last target is scaled by the 100 second solve time of
+		// block 29.
+		{29, 0x1e04ffe5},
+		{30, 0x1e0161cd},
+	}
+
+	for _, test := range tests {
+		newBlockTime := headers[test.lastHeight].Timestamp.Add(time.Minute)
+		bits, err := params.NextRequiredDifficulty(headers,
+			test.lastHeight, newBlockTime)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", test.lastHeight, err)
+			continue
+		}
+		if bits != test.want {
+			t.Errorf("%d: NextRequiredDifficulty = %08x, want %08x",
+				test.lastHeight, bits, test.want)
+		}
+	}
+}
+
+// TestValidateDifficultyAlgorithms ensures misordered and misconfigured
+// difficulty algorithms are rejected.
+func TestValidateDifficultyAlgorithms(t *testing.T) {
+	tests := []struct {
+		name  string
+		algos []DifficultyActivation
+		err   error
+	}{
+		{
+			name:  "none",
+			algos: nil,
+		},
+		{
+			name: "ascending",
+			algos: []DifficultyActivation{
+				{Height: 0, Algorithm: ClassicRetarget{}},
+				{Height: 100, Algorithm: DarkGravityWave{PastBlocks: 24}},
+				{Height: 200, Algorithm: LWMA{Window: 45}},
+			},
+		},
+		{
+			name: "descending",
+			algos: []DifficultyActivation{
+				{Height: 100, Algorithm: DarkGravityWave{PastBlocks: 24}},
+				{Height: 0, Algorithm: ClassicRetarget{}},
+			},
+			err: ErrDifficultyAlgorithmsNotAscending,
+		},
+		{
+			name: "duplicate height",
+			algos: []DifficultyActivation{
+				{Height: 0, Algorithm: ClassicRetarget{}},
+				{Height: 0, Algorithm: LWMA{Window: 45}},
+			},
+			err: ErrDifficultyAlgorithmsNotAscending,
+		},
+		{
+			name: "missing algorithm",
+			algos: []DifficultyActivation{
+				{Height: 0},
+			},
+			err: ErrInvalidDifficultyAlgorithm,
+		},
+		{
+			name: "dgw without past blocks",
+			algos: []DifficultyActivation{
+				{Height: 0, Algorithm: DarkGravityWave{}},
+			},
+			err: ErrInvalidDifficultyAlgorithm,
+		},
+		{
+			name: "lwma without window",
+			algos: []DifficultyActivation{
+				{Height: 0, Algorithm: LWMA{}},
+			},
+			err: ErrInvalidDifficultyAlgorithm,
+		},
+	}
+
+	for _, test := range tests {
+		params := Params{
+			TargetTimespan:       time.Hour,
+			TargetTimePerBlock:   time.Minute,
+			DifficultyAlgorithms: test.algos,
+		}
+		err := params.validateDifficultyAlgorithms()
+		if err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	// The classic retarget requires a positive target time per block and
+	// a target timespan covering at least one block whenever it can be
+	// selected, including implicitly before the first entry.
+	timingTests := []struct {
+		name               string
+		targetTimespan     time.Duration
+		targetTimePerBlock time.Duration
+		algos              []DifficultyActivation
+		err                error
+	}{
+		{
+			name:               "implicit classic without target time",
+			targetTimespan:     time.Hour,
+			targetTimePerBlock: 0,
+			err:                ErrInvalidDifficultyAlgorithm,
+		},
+		{
+			name:               "implicit classic with negative target time",
+			targetTimespan:     time.Hour,
+			targetTimePerBlock: -time.Minute,
+			err:                ErrInvalidDifficultyAlgorithm,
+		},
+		{
+			name:               "implicit classic with sub-second target time",
+			targetTimespan:     time.Millisecond,
+			targetTimePerBlock: time.Millisecond,
+			err:                ErrInvalidDifficultyAlgorithm,
+		},
+		{
+			name:               "implicit classic with short timespan",
+			targetTimespan:     time.Second,
+			targetTimePerBlock: time.Minute,
+			err:                ErrInvalidDifficultyAlgorithm,
+		},
+		{
+			name:               "classic before first entry",
+			targetTimespan:     0,
+			targetTimePerBlock: time.Minute,
+			algos: []DifficultyActivation{
+				{Height: 100, Algorithm: LWMA{Window: 45}},
+			},
+			err: ErrInvalidDifficultyAlgorithm,
+		},
+		{
+			name:               "explicit classic entry",
+			targetTimespan:     0,
+			targetTimePerBlock: time.Minute,
+			algos: []DifficultyActivation{
+				{Height: 0, Algorithm: LWMA{Window: 45}},
+				{Height: 100, Algorithm: ClassicRetarget{}},
+			},
+			err: ErrInvalidDifficultyAlgorithm,
+		},
+		{
+			name:               "classic never selected",
+			targetTimespan:     0,
+			targetTimePerBlock: time.Minute,
+			algos: []DifficultyActivation{
+				{Height: 0, Algorithm: LWMA{Window: 45}},
+			},
+		},
+		{
+			name:               "timespan of one block",
+			targetTimespan:     time.Minute,
+			targetTimePerBlock: time.Minute,
+		},
+	}
+	for _, test := range timingTests {
+		params := Params{
+			TargetTimespan:       test.targetTimespan,
+			TargetTimePerBlock:   test.targetTimePerBlock,
+			DifficultyAlgorithms: test.algos,
+		}
+		err := params.validateDifficultyAlgorithms()
+		if err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	// The per-block algorithms require a target time per block.
+	params := Params{
+		DifficultyAlgorithms: []DifficultyActivation{
+			{Height: 0, Algorithm: LWMA{Window: 45}},
+		},
+	}
+	err := params.validateDifficultyAlgorithms()
+	if err != ErrInvalidDifficultyAlgorithm {
+		t.Errorf("no target time per block: got %v, want %v", err,
+			ErrInvalidDifficultyAlgorithm)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// EstimateHeightAt estimates the height of the latest block mined at the passed
+// time given the height and time of a known anchor block, assuming blocks are
+// found every TargetTimePerBlock.  The estimate is never negative.
+func (p *Params) EstimateHeightAt(anchorHeight int32, anchorTime,
+	t time.Time) int32 {
+
+	// Round towards the past so the block is expected to exist by the
+	// passed time.
+	elapsed := t.Sub(anchorTime)
+	blocks := int64(elapsed / p.TargetTimePerBlock)
+	if elapsed < 0 && elapsed%p.TargetTimePerBlock != 0 {
+		blocks--
+	}
+
+	height := int64(anchorHeight) + blocks
+	switch {
+	case height < 0:
+		return 0
+	case height > math.MaxInt32:
+		return math.MaxInt32
+	}
+	return int32(height)
+}
+
+// EstimateTimeAt estimates the time the block at the passed height is mined
+// given the height and time of a known anchor block, assuming blocks are found
+// every TargetTimePerBlock.
+func (p *Params) EstimateTimeAt(anchorHeight int32, anchorTime time.Time,
+	height int32) time.Time {
+
+	// Saturate the offset rather than overflowing the duration for heights
+	// far away from the anchor.
+	blocks := int64(height) - int64(anchorHeight)
+	perBlock := int64(p.TargetTimePerBlock)
+	switch {
+	case blocks > math.MaxInt64/perBlock:
+		return anchorTime.Add(math.MaxInt64)
+	case blocks < math.MinInt64/perBlock:
+		return anchorTime.Add(math.MinInt64)
+	}
+	return anchorTime.Add(time.Duration(blocks * perBlock))
+}
+
+// NextHalving returns the height of the first block above the passed height
+// whose base subsidy differs from the previous block due to the subsidy
+// schedule, and whether there is such a block.  This is the start of the next
+// era when SubsidyEras is set and the next multiple of
+// SubsidyReductionInterval otherwise.
+func (p *Params) NextHalving(height int32) (int32, bool) {
+	if len(p.SubsidyEras) > 0 {
+		for _, era := range p.SubsidyEras {
+			if era.Height > height {
+				return era.Height, true
+			}
+		}
+		return 0, false
+	}
+
+	interval := int64(p.SubsidyReductionInterval)
+	if interval <= 0 {
+		return 0, false
+	}
+	next := int64(height)
+	if next < 0 {
+		next = 0
+	}
+	next = (next/interval + 1) * interval
+	if next > math.MaxInt32 {
+		return 0, false
+	}
+	return int32(next), true
+}
+
+// EstimateNextHalving returns the height of the next subsidy change after the
+// passed anchor block, as returned by NextHalving, along with the time it is
+// estimated to be mined given the height and time of the anchor block, and
+// whether there is such a change.
+func (p *Params) EstimateNextHalving(anchorHeight int32,
+	anchorTime time.Time) (int32, time.Time, bool) {
+
+	height, ok := p.NextHalving(anchorHeight)
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	return height, p.EstimateTimeAt(anchorHeight, anchorTime, height), true
+}
+
+// CalendarEventType identifies the kind of a calendar event.
+type CalendarEventType int
+
+// These constants define the kinds of calendar events.
+const (
+	// CalendarSubsidyChange is the change of the base subsidy at the
+	// start of a new subsidy era or after a halving.
+	CalendarSubsidyChange CalendarEventType = iota
+
+	// CalendarDeploymentStart is the start of the voting on a deployment.
+	CalendarDeploymentStart
+
+	// CalendarDeploymentTimeout is the expiration or timeout of a
+	// deployment which has not locked in.
+	CalendarDeploymentTimeout
+
+	// CalendarDeploymentMinActivation is the minimum activation height of
+	// a deployment.
+	CalendarDeploymentMinActivation
+)
+
+// calendarEventTypeStrings is a map of CalendarEventType values back to their
+// descriptions for pretty printing.
+var calendarEventTypeStrings = map[CalendarEventType]string{
+	CalendarSubsidyChange:           "subsidy change",
+	CalendarDeploymentStart:         "deployment start",
+	CalendarDeploymentTimeout:       "deployment timeout",
+	CalendarDeploymentMinActivation: "deployment minimum activation",
+}
+
+// String returns the CalendarEventType as a human-readable description.
+func (t CalendarEventType) String() string {
+	if s, ok := calendarEventTypeStrings[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown CalendarEventType (%d)", int(t))
+}
+
+// CalendarEvent describes an upcoming change in the rules or rewards of a
+// network.
+type CalendarEvent struct {
+	// Type is the kind of the event.
+	Type CalendarEventType
+
+	// Height and Time are the height and time of the event.  One of them
+	// is estimated from the other depending on whether the event is
+	// defined by a height or a time.
+	Height int32
+	Time   time.Time
+
+	// Deployment is the name of the deployment of deployment events.
+	Deployment string
+
+	// Subsidy is the new base subsidy of subsidy change events.
+	Subsidy int64
+}
+
+// Calendar returns the subsidy changes and deployment start, timeout and
+// minimum activation events of the network above anchorHeight and up to
+// endHeight, ordered by time.  The heights of time based events and the times
+// of height based events are estimated from the passed anchor block.
+func (p *Params) Calendar(anchorHeight int32, anchorTime time.Time,
+	endHeight int32) []CalendarEvent {
+
+	endTime := p.EstimateTimeAt(anchorHeight, anchorTime, endHeight)
+
+	var events []CalendarEvent
+	addHeightEvent := func(event CalendarEvent) {
+		if event.Height <= anchorHeight || event.Height > endHeight {
+			return
+		}
+		event.Time = p.EstimateTimeAt(anchorHeight, anchorTime,
+			event.Height)
+		events = append(events, event)
+	}
+	addTimeEvent := func(event CalendarEvent, unixTime uint64) {
+		if unixTime > math.MaxInt64 {
+			return
+		}
+		event.Time = time.Unix(int64(unixTime), 0)
+		if !event.Time.After(anchorTime) || event.Time.After(endTime) {
+			return
+		}
+		event.Height = p.EstimateHeightAt(anchorHeight, anchorTime,
+			event.Time)
+		events = append(events, event)
+	}
+
+	height, ok := p.NextHalving(anchorHeight)
+	for ok && height <= endHeight {
+		addHeightEvent(CalendarEvent{
+			Type:    CalendarSubsidyChange,
+			Height:  height,
+			Subsidy: p.baseSubsidy(height),
+		})
+		height, ok = p.NextHalving(height)
+	}
+
+	p.ForEachDeployment(func(d *ConsensusDeployment) bool {
+		start := CalendarEvent{
+			Type:       CalendarDeploymentStart,
+			Deployment: d.Name,
+		}
+		timeout := CalendarEvent{
+			Type:       CalendarDeploymentTimeout,
+			Deployment: d.Name,
+		}
+		if d.IsHeightBased() {
+			start.Height = d.StartHeight
+			addHeightEvent(start)
+			timeout.Height = d.TimeoutHeight
+			addHeightEvent(timeout)
+		} else {
+			addTimeEvent(start, d.StartTime)
+			addTimeEvent(timeout, d.ExpireTime)
+		}
+		if d.MinActivationHeight != 0 {
+			addHeightEvent(CalendarEvent{
+				Type:       CalendarDeploymentMinActivation,
+				Height:     d.MinActivationHeight,
+				Deployment: d.Name,
+			})
+		}
+		return true
+	})
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Time.Equal(events[j].Time) {
+			return events[i].Time.Before(events[j].Time)
+		}
+		return events[i].Height < events[j].Height
+	})
+	return events
+}
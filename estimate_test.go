@@ -0,0 +1,196 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestEstimateHeightAndTime ensures heights and times are estimated from the
+// anchor block and the target time per block.
+func TestEstimateHeightAndTime(t *testing.T) {
+	params := &Params{TargetTimePerBlock: time.Minute}
+	anchorTime := time.Unix(1500000000, 0)
+
+	heightTests := []struct {
+		offset time.Duration
+		want   int32
+	}{
+		{0, 1000},
+		{59 * time.Second, 1000},
+		{time.Minute, 1001},
+		{90 * time.Minute, 1090},
+		{-time.Second, 999},
+		{-time.Minute, 999},
+		{-61 * time.Second, 998},
+		{-1000 * time.Hour, 0},
+		{math.MaxInt64, 153723867},
+	}
+
+	for _, test := range heightTests {
+		height := params.EstimateHeightAt(1000, anchorTime,
+			anchorTime.Add(test.offset))
+		if height != test.want {
+			t.Errorf("EstimateHeightAt(anchor + %v) = %d, want %d",
+				test.offset, height, test.want)
+		}
+	}
+
+	timeTests := []struct {
+		height int32
+		want   time.Time
+	}{
+		{1000, anchorTime},
+		{1090, anchorTime.Add(90 * time.Minute)},
+		{900, anchorTime.Add(-100 * time.Minute)},
+		{100001000, anchorTime.Add(100000000 * time.Minute)},
+	}
+
+	for _, test := range timeTests {
+		got := params.EstimateTimeAt(1000, anchorTime, test.height)
+		if !got.Equal(test.want) {
+			t.Errorf("EstimateTimeAt(%d) = %v, want %v", test.height,
+				got, test.want)
+		}
+	}
+
+	// Heights which do not fit in 32 bits saturate.
+	fast := &Params{TargetTimePerBlock: time.Millisecond}
+	height := fast.EstimateHeightAt(0, anchorTime,
+		anchorTime.Add(math.MaxInt64))
+	if height != math.MaxInt32 {
+		t.Errorf("EstimateHeightAt saturated = %d, want %d", height,
+			math.MaxInt32)
+	}
+
+	// Offsets which do not fit in a duration saturate.
+	slow := &Params{TargetTimePerBlock: 1000 * time.Hour}
+	got := slow.EstimateTimeAt(0, anchorTime, math.MaxInt32)
+	if want := anchorTime.Add(math.MaxInt64); !got.Equal(want) {
+		t.Errorf("EstimateTimeAt saturated = %v, want %v", got, want)
+	}
+}
+
+// TestNextHalving ensures the next subsidy change follows the subsidy
+// schedule.
+func TestNextHalving(t *testing.T) {
+	eras := &Params{
+		SubsidyEras: []SubsidyEra{
+			{Height: 0, Subsidy: 100},
+			{Height: 500, Subsidy: 50},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		params *Params
+		height int32
+		want   int32
+		ok     bool
+	}{
+		{"mainnet genesis", &MainNetParams, 0, 1, true},
+		{"mainnet before era change", &MainNetParams, 72000, 72001, true},
+		{"mainnet at era change", &MainNetParams, 72001, 144001, true},
+		{"negative height", &MainNetParams, -5, 0, true},
+		{"mainnet last coin", &MainNetParams, 26280000, 26280001, true},
+		{"mainnet exhausted", &MainNetParams, 26280001, 0, false},
+		{"last halving", &SimNetParams, 2147460000, 0, false},
+		{"eras", eras, 0, 500, true},
+		{"after last era", eras, 500, 0, false},
+		{"no schedule", &Params{}, 0, 0, false},
+	}
+
+	for _, test := range tests {
+		height, ok := test.params.NextHalving(test.height)
+		if height != test.want || ok != test.ok {
+			t.Errorf("%s: NextHalving(%d) = %d, %v, want %d, %v",
+				test.name, test.height, height, ok, test.want,
+				test.ok)
+		}
+	}
+}
+
+// TestEstimateNextHalving ensures the next subsidy change is returned with
+// the time it is estimated to be mined.
+func TestEstimateNextHalving(t *testing.T) {
+	anchorTime := time.Unix(1500000000, 0)
+	params := &Params{
+		TargetTimePerBlock:       time.Minute,
+		SubsidyReductionInterval: 100,
+		BaseSubsidy:              64,
+	}
+
+	want := anchorTime.Add(50 * time.Minute)
+	height, estimate, ok := params.EstimateNextHalving(150, anchorTime)
+	if !ok || height != 200 || !estimate.Equal(want) {
+		t.Errorf("EstimateNextHalving(150) = %d, %v, %v, want 200, "+
+			"%v, true", height, estimate, ok, want)
+	}
+
+	// There is no next halving without a subsidy schedule.
+	height, estimate, ok = (&Params{}).EstimateNextHalving(150, anchorTime)
+	if ok || height != 0 || !estimate.IsZero() {
+		t.Errorf("EstimateNextHalving without schedule = %d, %v, %v, "+
+			"want 0, zero time, false", height, estimate, ok)
+	}
+}
+
+// TestCalendar ensures the calendar lists the subsidy changes and deployment
+// events within the requested heights in chronological order.
+func TestCalendar(t *testing.T) {
+	anchorTime := time.Unix(1500000000, 0)
+	params := &Params{
+		TargetTimePerBlock:       time.Minute,
+		SubsidyReductionInterval: 100,
+		BaseSubsidy:              64,
+		Deployments: []ConsensusDeployment{
+			{
+				Name:       "timed",
+				StartTime:  uint64(anchorTime.Unix()) + 30*60,
+				ExpireTime: uint64(anchorTime.Unix()) + 1000*60,
+			},
+			{
+				Name:                "heights",
+				StartHeight:         150,
+				TimeoutHeight:       250,
+				MinActivationHeight: 50,
+			},
+			{
+				Name:       "past",
+				StartTime:  0,
+				ExpireTime: math.MaxInt64,
+			},
+		},
+	}
+
+	events := params.Calendar(10, anchorTime, 260)
+	at := func(height int32) time.Time {
+		return anchorTime.Add(time.Duration(height-10) * time.Minute)
+	}
+	want := []CalendarEvent{
+		{Type: CalendarDeploymentStart, Height: 40, Time: at(40),
+			Deployment: "timed"},
+		{Type: CalendarDeploymentMinActivation, Height: 50, Time: at(50),
+			Deployment: "heights"},
+		{Type: CalendarSubsidyChange, Height: 100, Time: at(100),
+			Subsidy: 32},
+		{Type: CalendarDeploymentStart, Height: 150, Time: at(150),
+			Deployment: "heights"},
+		{Type: CalendarSubsidyChange, Height: 200, Time: at(200),
+			Subsidy: 16},
+		{Type: CalendarDeploymentTimeout, Height: 250, Time: at(250),
+			Deployment: "heights"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Calendar = %+v, want %+v", events, want)
+	}
+
+	if s := CalendarSubsidyChange.String(); s != "subsidy change" {
+		t.Errorf("CalendarSubsidyChange.String() = %q", s)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/ltcsuite/ltcd/wire"
+	"golang.org/x/crypto/sha3"
+)
+
+// SeedNetwork identifies the network of a fixed seed address using the
+// network IDs defined by BIP0155.
+type SeedNetwork uint8
+
+// These constants are the BIP0155 network IDs supported for fixed seeds.
+const (
+	// SeedNetIPv4 identifies an IPv4 address.
+	SeedNetIPv4 SeedNetwork = 1
+
+	// SeedNetIPv6 identifies an IPv6 address.
+	SeedNetIPv6 SeedNetwork = 2
+
+	// SeedNetTorV3 identifies a Tor v3 onion service by its ed25519
+	// public key.
+	SeedNetTorV3 SeedNetwork = 4
+
+	// SeedNetI2P identifies an I2P destination by the SHA256 hash of its
+	// destination.
+	SeedNetI2P SeedNetwork = 5
+)
+
+const (
+	// torV3Version is the version byte of Tor v3 onion addresses.
+	torV3Version = 0x03
+
+	// torV3Suffix and i2pSuffix are the suffixes of the hostnames of Tor
+	// v3 onion services and I2P destinations.
+	torV3Suffix = ".onion"
+	i2pSuffix   = ".b32.i2p"
+)
+
+// seedNetworkInfo houses the name and address length of each supported
+// network.
+var seedNetworkInfo = map[SeedNetwork]struct {
+	name    string
+	addrLen int
+}{
+	SeedNetIPv4:  {"ipv4", net.IPv4len},
+	SeedNetIPv6:  {"ipv6", net.IPv6len},
+	SeedNetTorV3: {"torv3", 32},
+	SeedNetI2P:   {"i2p", 32},
+}
+
+// seedBase32 is the unpadded lowercase base32 encoding used by onion and I2P
+// hostnames.
+var seedBase32 = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").
+	WithPadding(base32.NoPadding)
+
+var (
+	// ErrUnsupportedSeedNetwork describes an error where a fixed seed
+	// uses a network that is not supported.
+	ErrUnsupportedSeedNetwork = errors.New("unsupported seed address network")
+
+	// ErrInvalidSeedAddr describes an error where a fixed seed address can
+	// not be parsed or does not have the length required by its network.
+	ErrInvalidSeedAddr = errors.New("invalid seed address")
+)
+
+// String returns the SeedNetwork in human-readable form.
+func (n SeedNetwork) String() string {
+	if info, ok := seedNetworkInfo[n]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("Unknown SeedNetwork (%d)", uint8(n))
+}
+
+// SeedAddr is the address of a node that is hard-coded into the network
+// parameters in order to bootstrap when the DNS seeds are not available.
+type SeedAddr struct {
+	// Network identifies the network of the address.
+	Network SeedNetwork
+
+	// Addr is the address in its BIP0155 encoding for the network.
+	Addr []byte
+
+	// Port is the port of the node.  It is zero for I2P destinations.
+	Port uint16
+}
+
+// torV3Checksum returns the checksum of the onion address of the passed Tor
+// v3 public key.
+func torV3Checksum(pubKey []byte) []byte {
+	h := sha3.New256()
+	h.Write([]byte(".onion checksum"))
+	h.Write(pubKey)
+	h.Write([]byte{torV3Version})
+	return h.Sum(nil)[:2]
+}
+
+// parseSeedHost returns the network and BIP0155 encoding of the passed host.
+// IPv6 addresses may be enclosed in brackets.
+func parseSeedHost(host string) (SeedNetwork, []byte, error) {
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+
+	lower := strings.ToLower(host)
+	switch {
+	case strings.HasSuffix(lower, torV3Suffix):
+		data, err := seedBase32.DecodeString(
+			strings.TrimSuffix(lower, torV3Suffix))
+		if err != nil || len(data) != 35 || data[34] != torV3Version {
+			return 0, nil, ErrInvalidSeedAddr
+		}
+		pubKey := data[:32]
+		if !bytes.Equal(data[32:34], torV3Checksum(pubKey)) {
+			return 0, nil, ErrInvalidSeedAddr
+		}
+		return SeedNetTorV3, pubKey, nil
+
+	case strings.HasSuffix(lower, i2pSuffix):
+		data, err := seedBase32.DecodeString(
+			strings.TrimSuffix(lower, i2pSuffix))
+		if err != nil || len(data) != 32 {
+			return 0, nil, ErrInvalidSeedAddr
+		}
+		return SeedNetI2P, data, nil
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return 0, nil, ErrInvalidSeedAddr
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return SeedNetIPv4, ip4, nil
+	}
+	return SeedNetIPv6, ip.To16(), nil
+}
+
+// ParseSeedAddr parses a fixed seed address given as an IPv4 or IPv6 address,
+// the latter optionally enclosed in brackets, a Tor v3 onion hostname or an I2P
+// .b32.i2p hostname, optionally followed by a port as accepted by
+// net.SplitHostPort.  The passed default port is used
+// when the port is omitted, except for I2P destinations which always use port
+// zero as required by BIP0155.
+func ParseSeedAddr(addr string, defaultPort uint16) (SeedAddr, error) {
+	host, portStr := addr, ""
+	if h, p, err := net.SplitHostPort(addr); err == nil {
+		host, portStr = h, p
+	}
+
+	network, data, err := parseSeedHost(host)
+	if err != nil {
+		return SeedAddr{}, err
+	}
+
+	port := defaultPort
+	if portStr != "" {
+		p, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil {
+			return SeedAddr{}, ErrInvalidSeedAddr
+		}
+		port = uint16(p)
+	}
+	if network == SeedNetI2P {
+		port = 0
+	}
+	return SeedAddr{Network: network, Addr: data, Port: port}, nil
+}
+
+// validate returns an error when the address does not use a supported network
+// or does not have the length of its network.
+func (a *SeedAddr) validate() error {
+	info, ok := seedNetworkInfo[a.Network]
+	if !ok {
+		return ErrUnsupportedSeedNetwork
+	}
+	if len(a.Addr) != info.addrLen {
+		return ErrInvalidSeedAddr
+	}
+	return nil
+}
+
+// Host returns the host of the address, which is an IP address or an onion
+// or I2P hostname.
+func (a SeedAddr) Host() string {
+	switch a.Network {
+	case SeedNetIPv4, SeedNetIPv6:
+		return net.IP(a.Addr).String()
+
+	case SeedNetTorV3:
+		data := make([]byte, 0, 35)
+		data = append(data, a.Addr...)
+		data = append(data, torV3Checksum(a.Addr)...)
+		data = append(data, torV3Version)
+		return seedBase32.EncodeToString(data) + torV3Suffix
+
+	case SeedNetI2P:
+		return seedBase32.EncodeToString(a.Addr) + i2pSuffix
+	}
+	return ""
+}
+
+// String returns the address in host:port form.
+func (a SeedAddr) String() string {
+	return net.JoinHostPort(a.Host(), strconv.Itoa(int(a.Port)))
+}
+
+// validateFixedSeeds returns an error when a fixed seed of the network uses an
+// unsupported network or does not have the length of its network.
+func (p *Params) validateFixedSeeds() error {
+	for i := range p.FixedSeeds {
+		if err := p.FixedSeeds[i].validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BootstrapAddrs returns the addresses of the nodes to connect to in order to
+// bootstrap the network.  The addresses returned by the DNS seeds through the
+// passed resolver come first, followed by the fixed seeds of the network as a
+// fallback, without duplicates.  A SeedResolver for the network is created
+// when the passed resolver is nil.
+//
+// The failure of the DNS seeds is only reported when there are no fixed seeds
+// to fall back to.
+func (p *Params) BootstrapAddrs(ctx context.Context, resolver *SeedResolver,
+	reqServices wire.ServiceFlag) ([]string, error) {
+
+	if resolver == nil {
+		resolver = NewSeedResolver(p)
+	}
+	addrs, err := resolver.Resolve(ctx, reqServices)
+	if err != nil && len(p.FixedSeeds) == 0 {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		seen[addr] = struct{}{}
+	}
+	for _, seed := range p.FixedSeeds {
+		addr := seed.String()
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
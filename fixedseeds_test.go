@@ -0,0 +1,227 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// TestParseSeedAddr ensures fixed seed addresses of every supported network are
+// parsed into their BIP0155 encoding and formatted back.
+func TestParseSeedAddr(t *testing.T) {
+	const (
+		onion = "2gzyxa5ihm7nsggfxnu52rck2vv4rvmdlkiu3zzui5du4xyclen53wid.onion"
+		i2p   = "ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p"
+	)
+
+	tests := []struct {
+		name    string
+		addr    string
+		network SeedNetwork
+		addrLen int
+		want    string
+		err     error
+	}{
+		{
+			name:    "ipv4 default port",
+			addr:    "1.2.3.4",
+			network: SeedNetIPv4,
+			addrLen: 4,
+			want:    "1.2.3.4:41888",
+		},
+		{
+			name:    "ipv4 with port",
+			addr:    "1.2.3.4:8333",
+			network: SeedNetIPv4,
+			addrLen: 4,
+			want:    "1.2.3.4:8333",
+		},
+		{
+			name:    "ipv6 default port",
+			addr:    "2001:db8::1",
+			network: SeedNetIPv6,
+			addrLen: 16,
+			want:    "[2001:db8::1]:41888",
+		},
+		{
+			name:    "bracketed ipv6 default port",
+			addr:    "[::1]",
+			network: SeedNetIPv6,
+			addrLen: 16,
+			want:    "[::1]:41888",
+		},
+		{
+			name:    "ipv6 with port",
+			addr:    "[2001:db8::1]:8333",
+			network: SeedNetIPv6,
+			addrLen: 16,
+			want:    "[2001:db8::1]:8333",
+		},
+		{
+			name:    "tor v3",
+			addr:    onion + ":8333",
+			network: SeedNetTorV3,
+			addrLen: 32,
+			want:    onion + ":8333",
+		},
+		{
+			name:    "i2p ignores port",
+			addr:    i2p + ":8333",
+			network: SeedNetI2P,
+			addrLen: 32,
+			want:    i2p + ":0",
+		},
+		{
+			name: "tor v3 bad checksum",
+			addr: "2gzyxa5ihm7nsggfxnu52rck2vv4rvmdlkiu3zzui5du4xyclen53wia.onion",
+			err:  ErrInvalidSeedAddr,
+		},
+		{
+			name: "tor v2",
+			addr: "expyuzz4wqqyqhjn.onion",
+			err:  ErrInvalidSeedAddr,
+		},
+		{
+			name: "hostname",
+			addr: "seed.example.test",
+			err:  ErrInvalidSeedAddr,
+		},
+		{
+			name: "unbalanced brackets",
+			addr: "[::1",
+			err:  ErrInvalidSeedAddr,
+		},
+		{
+			name: "bad port",
+			addr: "1.2.3.4:70000",
+			err:  ErrInvalidSeedAddr,
+		},
+	}
+
+	for _, test := range tests {
+		seed, err := ParseSeedAddr(test.addr, 41888)
+		if err != test.err {
+			t.Errorf("%s: unexpected error: got %v, want %v",
+				test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if seed.Network != test.network || len(seed.Addr) != test.addrLen {
+			t.Errorf("%s: got network %v with %d bytes, want %v "+
+				"with %d bytes", test.name, seed.Network,
+				len(seed.Addr), test.network, test.addrLen)
+		}
+		if got := seed.String(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got,
+				test.want)
+		}
+		if err := seed.validate(); err != nil {
+			t.Errorf("%s: unexpected validation error: %v",
+				test.name, err)
+		}
+	}
+}
+
+// TestValidateFixedSeeds ensures malformed fixed seeds are rejected.
+func TestValidateFixedSeeds(t *testing.T) {
+	tests := []struct {
+		name  string
+		seeds []SeedAddr
+		err   error
+	}{
+		{
+			name:  "no seeds",
+			seeds: nil,
+		},
+		{
+			name: "valid",
+			seeds: []SeedAddr{
+				{SeedNetIPv4, []byte{1, 2, 3, 4}, 41888},
+				{SeedNetI2P, bytes.Repeat([]byte{1}, 32), 0},
+			},
+		},
+		{
+			name: "short ipv6",
+			seeds: []SeedAddr{
+				{SeedNetIPv6, []byte{1, 2, 3, 4}, 41888},
+			},
+			err: ErrInvalidSeedAddr,
+		},
+		{
+			name: "unsupported network",
+			seeds: []SeedAddr{
+				{SeedNetwork(6), bytes.Repeat([]byte{1}, 16), 41888},
+			},
+			err: ErrUnsupportedSeedNetwork,
+		},
+	}
+
+	for _, test := range tests {
+		params := &Params{FixedSeeds: test.seeds}
+		if err := params.validateFixedSeeds(); err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+// TestBootstrapAddrs ensures the fixed seeds are appended to the addresses of
+// the DNS seeds without duplicates and are used as a fallback when the DNS
+// seeds fail.
+func TestBootstrapAddrs(t *testing.T) {
+	server := newTestDNSServer(t, map[string][]net.IP{
+		"seed.example.test": {
+			net.ParseIP("10.0.0.1"),
+			net.ParseIP("10.0.0.2"),
+		},
+	}, nil)
+
+	params := &Params{
+		DefaultPort: "41888",
+		DNSSeeds:    []DNSSeed{{"seed.example.test", false}},
+		FixedSeeds: []SeedAddr{
+			{SeedNetIPv4, []byte{10, 0, 0, 2}, 41888},
+			{SeedNetIPv4, []byte{10, 0, 0, 3}, 41888},
+		},
+	}
+	resolver := NewSeedResolver(params)
+	resolver.Dial = server.dial
+
+	addrs, err := params.BootstrapAddrs(context.Background(), resolver,
+		wire.SFNodeNetwork)
+	if err != nil {
+		t.Fatalf("BootstrapAddrs: unexpected error: %v", err)
+	}
+	want := []string{"10.0.0.1:41888", "10.0.0.2:41888", "10.0.0.3:41888"}
+	if !reflect.DeepEqual(addrs, want) {
+		t.Fatalf("BootstrapAddrs: got %v, want %v", addrs, want)
+	}
+
+	// Fall back to the fixed seeds when the DNS seeds fail.
+	params.DNSSeeds = []DNSSeed{{"missing.example.test", false}}
+	addrs, err = params.BootstrapAddrs(context.Background(), resolver,
+		wire.SFNodeNetwork)
+	if err != nil {
+		t.Fatalf("BootstrapAddrs: unexpected error: %v", err)
+	}
+	want = []string{"10.0.0.2:41888", "10.0.0.3:41888"}
+	if !reflect.DeepEqual(addrs, want) {
+		t.Fatalf("BootstrapAddrs: got %v, want %v", addrs, want)
+	}
+
+	// The DNS failure is reported without fixed seeds.
+	params.FixedSeeds = nil
+	if _, err := params.BootstrapAddrs(context.Background(), resolver,
+		wire.SFNodeNetwork); err == nil {
+		t.Fatal("BootstrapAddrs: expected error without fixed seeds")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"sort"
+)
+
+// These constants define the names of the forks which have dedicated height
+// fields on Params.
+const (
+	// ForkBIP0034 is the name of the fork which requires coinbase
+	// transactions to commit to the block height.
+	ForkBIP0034 = "bip34"
+
+	// ForkBIP0065 is the name of the fork which introduces
+	// OP_CHECKLOCKTIMEVERIFY.
+	ForkBIP0065 = "bip65"
+
+	// ForkBIP0066 is the name of the fork which requires strict DER
+	// signatures.
+	ForkBIP0066 = "bip66"
+)
+
+// ErrDuplicateFork describes an error where a network declares the same fork
+// more than once.
+var ErrDuplicateFork = errors.New("duplicate fork")
+
+// Fork defines a consensus rule change which activates at a fixed height.
+type Fork struct {
+	// Name uniquely identifies the fork within a network.
+	Name string
+
+	// Height is the height of the first block the rule change applies
+	// to.
+	Height int32
+}
+
+// forks returns the fork table of the network.  It contains the Forks of the
+// network followed by the forks defined by the BIP0034Height, BIP0065Height
+// and BIP0066Height fields which are not overridden by it.
+func (p *Params) forks() []Fork {
+	forks := make([]Fork, 0, len(p.Forks)+3)
+	forks = append(forks, p.Forks...)
+
+	legacy := []Fork{
+		{Name: ForkBIP0034, Height: p.BIP0034Height},
+		{Name: ForkBIP0065, Height: p.BIP0065Height},
+		{Name: ForkBIP0066, Height: p.BIP0066Height},
+	}
+	for _, fork := range legacy {
+		if _, ok := p.forkHeight(fork.Name); !ok {
+			forks = append(forks, fork)
+		}
+	}
+	return forks
+}
+
+// forkHeight returns the activation height of the named fork declared in the
+// Forks of the network and whether it is declared.
+func (p *Params) forkHeight(name string) (int32, bool) {
+	for _, fork := range p.Forks {
+		if fork.Name == name {
+			return fork.Height, true
+		}
+	}
+	return 0, false
+}
+
+// ActivationHeight returns the activation height of the named fork and whether
+// the network defines it.  The BIP0034, BIP0065 and BIP0066 forks are always
+// defined, by their height fields unless Forks overrides them.
+func (p *Params) ActivationHeight(fork string) (int32, bool) {
+	if height, ok := p.forkHeight(fork); ok {
+		return height, true
+	}
+
+	switch fork {
+	case ForkBIP0034:
+		return p.BIP0034Height, true
+	case ForkBIP0065:
+		return p.BIP0065Height, true
+	case ForkBIP0066:
+		return p.BIP0066Height, true
+	}
+	return 0, false
+}
+
+// IsActive returns whether the rules of the named fork apply to the block at
+// the passed height.  Forks the network does not define are never active.
+func (p *Params) IsActive(fork string, height int32) bool {
+	activationHeight, ok := p.ActivationHeight(fork)
+	return ok && height >= activationHeight
+}
+
+// ActiveForks returns the names of the forks whose rules apply to the block at
+// the passed height, ordered by activation height and then by name.
+func (p *Params) ActiveForks(height int32) []string {
+	var active []Fork
+	for _, fork := range p.forks() {
+		if height >= fork.Height {
+			active = append(active, fork)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].Height != active[j].Height {
+			return active[i].Height < active[j].Height
+		}
+		return active[i].Name < active[j].Name
+	})
+
+	names := make([]string, 0, len(active))
+	for _, fork := range active {
+		names = append(names, fork.Name)
+	}
+	return names
+}
+
+// validateForks ensures every fork in the Forks of the network has a unique
+// name.
+func (p *Params) validateForks() error {
+	seen := make(map[string]struct{}, len(p.Forks))
+	for _, fork := range p.Forks {
+		if _, ok := seen[fork.Name]; ok {
+			return ErrDuplicateFork
+		}
+		seen[fork.Name] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestForks ensures the fork table combines the BIP height fields with the
+// declared forks.
+func TestForks(t *testing.T) {
+	params := &Params{
+		TargetTimespan:     time.Hour,
+		TargetTimePerBlock: time.Minute,
+		BIP0034Height:      100,
+		BIP0065Height:      200,
+		BIP0066Height:      300,
+		Forks: []Fork{
+			{Name: "emc2-hardfork", Height: 250},
+			{Name: ForkBIP0066, Height: 150},
+		},
+	}
+
+	heightTests := []struct {
+		fork   string
+		height int32
+		ok     bool
+	}{
+		{ForkBIP0034, 100, true},
+		{ForkBIP0065, 200, true},
+		{ForkBIP0066, 150, true},
+		{"emc2-hardfork", 250, true},
+		{"unknown", 0, false},
+	}
+
+	for _, test := range heightTests {
+		height, ok := params.ActivationHeight(test.fork)
+		if height != test.height || ok != test.ok {
+			t.Errorf("ActivationHeight(%q) = %d, %v, want %d, %v",
+				test.fork, height, ok, test.height, test.ok)
+		}
+	}
+
+	activeTests := []struct {
+		fork   string
+		height int32
+		active bool
+	}{
+		{ForkBIP0034, 99, false},
+		{ForkBIP0034, 100, true},
+		{ForkBIP0066, 149, false},
+		{ForkBIP0066, 150, true},
+		{"emc2-hardfork", 249, false},
+		{"emc2-hardfork", 250, true},
+		{"unknown", 1 << 30, false},
+	}
+
+	for _, test := range activeTests {
+		active := params.IsActive(test.fork, test.height)
+		if active != test.active {
+			t.Errorf("IsActive(%q, %d) = %v, want %v", test.fork,
+				test.height, active, test.active)
+		}
+	}
+
+	listTests := []struct {
+		height int32
+		want   []string
+	}{
+		{99, []string{}},
+		{100, []string{ForkBIP0034}},
+		{150, []string{ForkBIP0034, ForkBIP0066}},
+		{300, []string{ForkBIP0034, ForkBIP0066, ForkBIP0065,
+			"emc2-hardfork"}},
+	}
+
+	for _, test := range listTests {
+		forks := params.ActiveForks(test.height)
+		if !reflect.DeepEqual(forks, test.want) {
+			t.Errorf("ActiveForks(%d) = %v, want %v", test.height,
+				forks, test.want)
+		}
+	}
+
+	params.Forks = append(params.Forks, Fork{Name: "emc2-hardfork"})
+	if err := params.Validate(); err != ErrDuplicateFork {
+		t.Errorf("Validate = %v, want %v", err, ErrDuplicateFork)
+	}
+}
+
+// TestDefaultNetworkForks ensures the BIP height fields of the default
+// networks are exposed through the fork table.
+func TestDefaultNetworkForks(t *testing.T) {
+	if !MainNetParams.IsActive(ForkBIP0034, MainNetParams.BIP0034Height) {
+		t.Errorf("BIP0034 is not active at its mainnet height")
+	}
+	if RegressionNetParams.IsActive(ForkBIP0034, 1000) {
+		t.Errorf("BIP0034 is active on regtest")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+// This program selects checkpoint candidates from a file of serialized block
+// headers and prints them as Checkpoint literals suitable for the network
+// parameters in params.go.
+//
+// The file must contain the 80-byte serialized headers of the main chain back
+// to back in ascending height order, starting with the header at the height
+// given by -start.
+//
+// Usage:
+//
+//	go run gencheckpoints.go -headers headers.dat -after 0 -spacing 50000
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// headerFile implements the chaincfg.HeaderProvider interface for the headers
+// read from a header dump.
+type headerFile struct {
+	startHeight int32
+	headers     []wire.BlockHeader
+}
+
+// HeaderByHeight returns the header at the passed height.
+//
+// This is part of the chaincfg.HeaderProvider interface.
+func (f *headerFile) HeaderByHeight(height int32) (*wire.BlockHeader, error) {
+	i := int64(height) - int64(f.startHeight)
+	if i < 0 || i >= int64(len(f.headers)) {
+		return nil, fmt.Errorf("no header at height %d in the header "+
+			"dump", height)
+	}
+	return &f.headers[i], nil
+}
+
+// tipHeight returns the height of the last header of the dump.
+func (f *headerFile) tipHeight() int32 {
+	return f.startHeight + int32(len(f.headers)) - 1
+}
+
+// readHeaders reads the serialized headers from the passed reader until it is
+// exhausted.
+func readHeaders(r io.Reader, startHeight int32) (*headerFile, error) {
+	f := &headerFile{startHeight: startHeight}
+	br := bufio.NewReader(r)
+	for {
+		if _, err := br.Peek(1); err == io.EOF {
+			return f, nil
+		}
+
+		var header wire.BlockHeader
+		if err := header.Deserialize(br); err != nil {
+			return nil, fmt.Errorf("header at height %d: %v",
+				f.tipHeight()+1, err)
+		}
+		f.headers = append(f.headers, header)
+	}
+}
+
+func main() {
+	path := flag.String("headers", "", "file of serialized block headers")
+	start := flag.Int("start", 0, "height of the first header in the file")
+	after := flag.Int("after", 0, "only select blocks above this height, "+
+		"usually the height of the latest checkpoint")
+	spacing := flag.Int("spacing", 0, "minimum number of blocks between "+
+		"checkpoints")
+	confirmations := flag.Int("confirmations",
+		chaincfg.DefaultCheckpointConfirmations, "minimum number of "+
+			"blocks built on top of a checkpoint")
+	flag.Parse()
+
+	if *path == "" {
+		fmt.Fprintln(os.Stderr, "a header file must be given with -headers")
+		os.Exit(1)
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	headers, err := readHeaders(file, int32(*start))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	candidates, err := chaincfg.CheckpointCandidates(headers,
+		headers.tipHeight(), chaincfg.CheckpointCandidateConfig{
+			Confirmations: int32(*confirmations),
+			MinSpacing:    int32(*spacing),
+			AfterHeight:   int32(*after),
+			FirstHeight:   int32(*start),
+		})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for _, checkpoint := range candidates {
+		fmt.Printf("\t\t{%d, newHashFromStr(\"%v\")},\n",
+			checkpoint.Height, checkpoint.Hash)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+// This program converts a plain-text list of node addresses into the Go source
+// of a list of fixed seeds suitable for the FixedSeeds field of the network
+// parameters.
+//
+// The list contains one address per line as accepted by ParseSeedAddr, that is
+// an IPv4 or IPv6 address, a Tor v3 onion hostname or an I2P .b32.i2p
+// hostname, optionally followed by a port.  Empty lines and text following a
+// '#' are ignored.
+//
+// Usage:
+//
+//	go run genseeds.go -nodes nodes_main.txt -var mainNetFixedSeeds -port 41888
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+)
+
+// seedNetworkNames maps the networks of the fixed seeds to the names of their
+// constants in the chaincfg package.
+var seedNetworkNames = map[chaincfg.SeedNetwork]string{
+	chaincfg.SeedNetIPv4:  "SeedNetIPv4",
+	chaincfg.SeedNetIPv6:  "SeedNetIPv6",
+	chaincfg.SeedNetTorV3: "SeedNetTorV3",
+	chaincfg.SeedNetI2P:   "SeedNetI2P",
+}
+
+// readNodes parses the node list read from the passed reader.
+func readNodes(r io.Reader, defaultPort uint16) ([]chaincfg.SeedAddr, error) {
+	var seeds []chaincfg.SeedAddr
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		seed, err := chaincfg.ParseSeedAddr(line, defaultPort)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %q: %v", lineNum, line,
+				err)
+		}
+		seeds = append(seeds, seed)
+	}
+	return seeds, scanner.Err()
+}
+
+// generate returns the formatted Go source declaring the passed seeds as a
+// variable with the passed name.
+func generate(varName string, seeds []chaincfg.SeedAddr) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by genseeds.go. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package chaincfg")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "var %s = []SeedAddr{\n", varName)
+	for _, seed := range seeds {
+		fmt.Fprintf(&buf, "{%s, []byte{", seedNetworkNames[seed.Network])
+		for i, b := range seed.Addr {
+			if i > 0 {
+				fmt.Fprint(&buf, ", ")
+			}
+			fmt.Fprintf(&buf, "0x%02x", b)
+		}
+		fmt.Fprintf(&buf, "}, %d}, // %s\n", seed.Port, seed.Host())
+	}
+	fmt.Fprintln(&buf, "}")
+	return format.Source(buf.Bytes())
+}
+
+func main() {
+	nodesFile := flag.String("nodes", "", "file containing the node list")
+	varName := flag.String("var", "", "name of the generated variable")
+	port := flag.Uint("port", 0, "default port of the nodes")
+	flag.Parse()
+
+	if *nodesFile == "" || *varName == "" || *port > 0xffff {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	f, err := os.Open(*nodesFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	seeds, err := readNodes(f, uint16(*port))
+	f.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	src, err := generate(*varName, seeds)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(src)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+var (
+	// ErrUnknownMagic describes an error where the magic bytes of a
+	// message do not identify a registered network or a well-known
+	// foreign one.
+	ErrUnknownMagic = errors.New("unknown network magic")
+
+	// ErrForeignMagic describes an error where the magic bytes of a
+	// message only identify a well-known network of another chain.
+	ErrForeignMagic = errors.New("foreign network magic")
+)
+
+// foreignMagics maps the magic bytes of the networks of well-known foreign
+// chains, in the order they appear on the wire, to the name of the networks.
+// Some of them are shared by the default networks, which inherited them from
+// Litecoin.
+var foreignMagics = map[[4]byte]string{
+	{0xf9, 0xbe, 0xb4, 0xd9}: "bitcoin mainnet",
+	{0x0b, 0x11, 0x09, 0x07}: "bitcoin testnet3",
+	{0x1c, 0x16, 0x3f, 0x28}: "bitcoin testnet4",
+	{0x0a, 0x03, 0xcf, 0x40}: "bitcoin signet",
+	{0xfa, 0xbf, 0xb5, 0xda}: "bitcoin/litecoin regtest",
+	{0xfb, 0xc0, 0xb6, 0xdb}: "litecoin mainnet",
+	{0xfd, 0xd2, 0xc8, 0xf1}: "litecoin testnet4",
+}
+
+// NetworkForMagic returns the parameters of the registered network identified
+// by the passed magic bytes, in the order they appear on the wire, along with
+// the name of the well-known foreign network which uses the same magic, if any.
+//
+// A registered network which shares its magic with a foreign network is
+// returned along with the name of that network and no error, since the magic
+// alone can not tell them apart and callers must decide whether that matters
+// to them.  When no registered network uses the magic, ErrForeignMagic is
+// returned with the name of the foreign network if the magic belongs to one,
+// and ErrUnknownMagic otherwise.
+func NetworkForMagic(magic [4]byte) (*Params, string, error) {
+	foreign := foreignMagics[magic]
+	net := wire.BitcoinNet(binary.LittleEndian.Uint32(magic[:]))
+	if params, ok := registeredNets[net]; ok {
+		return params, foreign, nil
+	}
+	if foreign != "" {
+		return nil, foreign, ErrForeignMagic
+	}
+	return nil, "", ErrUnknownMagic
+}
+
+// DetectNetFromStream reads the magic bytes which start every message of the
+// peer-to-peer protocol from the passed reader and returns the parameters of
+// the network they identify and the name of the foreign network sharing them
+// as described by NetworkForMagic.  Only the first four bytes of the stream are
+// consumed.  io.EOF or io.ErrUnexpectedEOF is returned when the stream ends
+// before them.
+func DetectNetFromStream(r io.Reader) (*Params, string, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, "", err
+	}
+	return NetworkForMagic(magic)
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// wireMagic returns the magic bytes of the passed network in wire order.
+func wireMagic(net wire.BitcoinNet) [4]byte {
+	var magic [4]byte
+	binary.LittleEndian.PutUint32(magic[:], uint32(net))
+	return magic
+}
+
+// TestNetworkForMagic ensures registered networks are found by their magic
+// bytes along with the foreign networks sharing them, and that other magics
+// are reported as foreign or unknown.
+func TestNetworkForMagic(t *testing.T) {
+	tests := []struct {
+		name    string
+		magic   [4]byte
+		want    *Params
+		foreign string
+		err     error
+	}{
+		{
+			// The default networks other than simnet use the
+			// magics of Litecoin, which can not be told apart.
+			name:    "litecoin mainnet",
+			magic:   [4]byte{0xfb, 0xc0, 0xb6, 0xdb},
+			want:    &MainNetParams,
+			foreign: "litecoin mainnet",
+		},
+		{
+			name:    "litecoin testnet4",
+			magic:   [4]byte{0xfd, 0xd2, 0xc8, 0xf1},
+			want:    &TestNet4Params,
+			foreign: "litecoin testnet4",
+		},
+		{
+			name:    "litecoin regtest",
+			magic:   [4]byte{0xfa, 0xbf, 0xb5, 0xda},
+			want:    &RegressionNetParams,
+			foreign: "bitcoin/litecoin regtest",
+		},
+		{
+			name:    "mainnet",
+			magic:   wireMagic(MainNetParams.Net),
+			want:    &MainNetParams,
+			foreign: "litecoin mainnet",
+		},
+		{
+			name:  "simnet",
+			magic: wireMagic(SimNetParams.Net),
+			want:  &SimNetParams,
+		},
+		{
+			name:    "bitcoin mainnet",
+			magic:   [4]byte{0xf9, 0xbe, 0xb4, 0xd9},
+			foreign: "bitcoin mainnet",
+			err:     ErrForeignMagic,
+		},
+		{
+			name:    "bitcoin signet",
+			magic:   [4]byte{0x0a, 0x03, 0xcf, 0x40},
+			foreign: "bitcoin signet",
+			err:     ErrForeignMagic,
+		},
+		{
+			name:  "unknown",
+			magic: [4]byte{0x01, 0x02, 0x03, 0x04},
+			err:   ErrUnknownMagic,
+		},
+	}
+
+	for _, test := range tests {
+		params, foreign, err := NetworkForMagic(test.magic)
+		if err != test.err {
+			t.Errorf("%s: unexpected error: got %v, want %v",
+				test.name, err, test.err)
+			continue
+		}
+		if params != test.want {
+			t.Errorf("%s: got network %v, want %v", test.name,
+				params, test.want)
+		}
+		if foreign != test.foreign {
+			t.Errorf("%s: got foreign network %q, want %q",
+				test.name, foreign, test.foreign)
+		}
+	}
+}
+
+// TestDetectNetFromStream ensures the network is detected from the first four
+// bytes of a stream without consuming more of it.
+func TestDetectNetFromStream(t *testing.T) {
+	magic := wireMagic(SimNetParams.Net)
+	r := bytes.NewReader(append(magic[:], "version"...))
+	params, foreign, err := DetectNetFromStream(r)
+	if err != nil {
+		t.Fatalf("DetectNetFromStream: unexpected error: %v", err)
+	}
+	if params != &SimNetParams || foreign != "" {
+		t.Fatalf("DetectNetFromStream: got %v, %q, want simnet",
+			params.Name, foreign)
+	}
+	if r.Len() != len("version") {
+		t.Fatalf("DetectNetFromStream: consumed %d bytes, want 4",
+			int(r.Size())-r.Len())
+	}
+
+	// Streams of networks sharing their magic with Litecoin name the
+	// foreign network without an error.
+	magic = wireMagic(MainNetParams.Net)
+	params, foreign, err = DetectNetFromStream(bytes.NewReader(magic[:]))
+	if params != &MainNetParams || foreign != "litecoin mainnet" ||
+		err != nil {
+
+		t.Fatalf("DetectNetFromStream: got %v, %q, %v, want mainnet, "+
+			"%q, <nil>", params, foreign, err, "litecoin mainnet")
+	}
+
+	_, _, err = DetectNetFromStream(bytes.NewReader(magic[:2]))
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("DetectNetFromStream: got %v, want %v", err,
+			io.ErrUnexpectedEOF)
+	}
+
+	_, _, err = DetectNetFromStream(bytes.NewReader(nil))
+	if err != io.EOF {
+		t.Fatalf("DetectNetFromStream: got %v, want %v", err, io.EOF)
+	}
+}
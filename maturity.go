@@ -0,0 +1,117 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrMaturityErasNotAscending describes an error where the heights of the
+// coinbase maturity eras of a network are not strictly ascending.
+var ErrMaturityErasNotAscending = errors.New("coinbase maturity era heights " +
+	"are not strictly ascending")
+
+// MaturityEra defines the coinbase maturity which applies to transactions
+// spending coinbase outputs in blocks from a given height onwards.
+type MaturityEra struct {
+	// Height is the height of the first spending block the maturity
+	// applies to.
+	Height int32
+
+	// Maturity is the number of blocks required before coinbase outputs
+	// can be spent.
+	Maturity uint16
+}
+
+// CoinbaseMaturityAt returns the number of blocks which must separate a
+// coinbase transaction from a block at the passed height spending it.  This is
+// CoinbaseMaturity before the first of the CoinbaseMaturityEras.
+func (p *Params) CoinbaseMaturityAt(spendHeight int32) uint16 {
+	// Find the first era which starts above the height.  The one right
+	// before it, if any, applies to the spending block.
+	eras := p.CoinbaseMaturityEras
+	i := sort.Search(len(eras), func(i int) bool {
+		return eras[i].Height > spendHeight
+	})
+	if i == 0 {
+		return p.CoinbaseMaturity
+	}
+	return eras[i-1].Maturity
+}
+
+// IsCoinbaseMature returns whether the outputs of the coinbase transaction of
+// the block at coinbaseHeight may be spent by a transaction in the block at
+// spendHeight.
+//
+// This mirrors the reference implementation which requires the difference
+// between the two heights to be at least the coinbase maturity that applies at
+// the spending height.  For example, with a maturity of 100, the coinbase of
+// block 1 can first be spent in block 101.
+func (p *Params) IsCoinbaseMature(coinbaseHeight, spendHeight int32) bool {
+	maturity := int64(p.CoinbaseMaturityAt(spendHeight))
+	return int64(spendHeight)-int64(coinbaseHeight) >= maturity
+}
+
+// CoinbaseSpendableHeight returns the height of the first block which may
+// spend the outputs of the coinbase transaction of the block at the passed
+// height.  See IsCoinbaseMature for details.
+func (p *Params) CoinbaseSpendableHeight(coinbaseHeight int32) int32 {
+	// The maturity depends on the spending height, so find the first era
+	// containing a height which is far enough from the coinbase.  Before
+	// the first era, CoinbaseMaturity applies from the lowest height.
+	start := int64(math.MinInt32)
+	maturity := p.CoinbaseMaturity
+	for _, era := range p.CoinbaseMaturityEras {
+		height := int64(coinbaseHeight) + int64(maturity)
+		if height < start {
+			height = start
+		}
+		if height < int64(era.Height) {
+			return int32(height)
+		}
+		start, maturity = int64(era.Height), era.Maturity
+	}
+
+	height := int64(coinbaseHeight) + int64(maturity)
+	if height < start {
+		height = start
+	}
+	if height > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(height)
+}
+
+// CoinbaseBlocksToMaturity returns the number of blocks which must still be
+// added on top of the block at tipHeight before a wallet considers the outputs
+// of the coinbase transaction of the block at coinbaseHeight mature.  It is
+// zero once they are.
+//
+// Like the reference wallet, a coinbase is only reported as mature once the
+// chain includes the block at its spendable height, which is one block later
+// than a transaction spending it could first be mined.  For example, with a
+// maturity of 100, the coinbase of block 1 has one block to go with a tip at
+// height 100 and is mature with a tip at height 101.
+func (p *Params) CoinbaseBlocksToMaturity(coinbaseHeight, tipHeight int32) int32 {
+	remaining := p.CoinbaseSpendableHeight(coinbaseHeight) - tipHeight
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// validateMaturityEras ensures the heights of the coinbase maturity eras are
+// strictly ascending.
+func (p *Params) validateMaturityEras() error {
+	eras := p.CoinbaseMaturityEras
+	for i := 1; i < len(eras); i++ {
+		if eras[i].Height <= eras[i-1].Height {
+			return ErrMaturityErasNotAscending
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestCoinbaseMaturity ensures the coinbase maturity helpers match the
+// semantics of the reference implementation.
+func TestCoinbaseMaturity(t *testing.T) {
+	params := &MainNetParams
+
+	tests := []struct {
+		coinbaseHeight int32
+		spendHeight    int32
+		mature         bool
+	}{
+		{0, 0, false},
+		{0, 99, false},
+		{0, 100, true},
+		{1, 100, false},
+		{1, 101, true},
+		{1000, 1099, false},
+		{1000, 1100, true},
+		{1000, 5000, true},
+	}
+
+	for _, test := range tests {
+		mature := params.IsCoinbaseMature(test.coinbaseHeight,
+			test.spendHeight)
+		if mature != test.mature {
+			t.Errorf("IsCoinbaseMature(%d, %d) = %v, want %v",
+				test.coinbaseHeight, test.spendHeight, mature,
+				test.mature)
+		}
+	}
+
+	spendableTests := []struct {
+		coinbaseHeight int32
+		want           int32
+	}{
+		{0, 100},
+		{1, 101},
+		{1000, 1100},
+		{math.MaxInt32 - 10, math.MaxInt32},
+	}
+
+	for _, test := range spendableTests {
+		height := params.CoinbaseSpendableHeight(test.coinbaseHeight)
+		if height != test.want {
+			t.Errorf("CoinbaseSpendableHeight(%d) = %d, want %d",
+				test.coinbaseHeight, height, test.want)
+		}
+	}
+
+	// The reference wallet reports the coinbase of block 1 as immature
+	// until block 101 is part of the chain.
+	walletTests := []struct {
+		coinbaseHeight int32
+		tipHeight      int32
+		want           int32
+	}{
+		{1, 1, 100},
+		{1, 50, 51},
+		{1, 100, 1},
+		{1, 101, 0},
+		{1, 1000, 0},
+	}
+
+	for _, test := range walletTests {
+		remaining := params.CoinbaseBlocksToMaturity(
+			test.coinbaseHeight, test.tipHeight)
+		if remaining != test.want {
+			t.Errorf("CoinbaseBlocksToMaturity(%d, %d) = %d, want %d",
+				test.coinbaseHeight, test.tipHeight, remaining,
+				test.want)
+		}
+	}
+}
+
+// TestCoinbaseMaturityEras ensures the coinbase maturity follows the maturity
+// schedule of the spending block.
+func TestCoinbaseMaturityEras(t *testing.T) {
+	params := &Params{
+		TargetTimespan:     time.Hour,
+		TargetTimePerBlock: time.Minute,
+		CoinbaseMaturity:   100,
+		CoinbaseMaturityEras: []MaturityEra{
+			{Height: 1000, Maturity: 30},
+			{Height: 2000, Maturity: 240},
+		},
+	}
+
+	tests := []struct {
+		coinbaseHeight int32
+		want           int32
+	}{
+		{0, 100},
+		{899, 999},
+		{900, 1000},
+		{950, 1000},
+		{975, 1005},
+		{1969, 1999},
+		{1970, 2210},
+		{2000, 2240},
+	}
+
+	for _, test := range tests {
+		height := params.CoinbaseSpendableHeight(test.coinbaseHeight)
+		if height != test.want {
+			t.Errorf("CoinbaseSpendableHeight(%d) = %d, want %d",
+				test.coinbaseHeight, height, test.want)
+		}
+	}
+
+	// The spendable height must be the first height at which the coinbase
+	// is mature.
+	for coinbaseHeight := int32(0); coinbaseHeight < 2500; coinbaseHeight++ {
+		want := coinbaseHeight
+		for !params.IsCoinbaseMature(coinbaseHeight, want) {
+			want++
+		}
+		height := params.CoinbaseSpendableHeight(coinbaseHeight)
+		if height != want {
+			t.Fatalf("CoinbaseSpendableHeight(%d) = %d, want %d",
+				coinbaseHeight, height, want)
+		}
+	}
+
+	params.CoinbaseMaturityEras = append(params.CoinbaseMaturityEras,
+		MaturityEra{Height: 2000, Maturity: 100})
+	if err := params.Validate(); err != ErrMaturityErasNotAscending {
+		t.Errorf("Validate = %v, want %v", err,
+			ErrMaturityErasNotAscending)
+	}
+}
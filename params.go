@@ -42,6 +42,44 @@ var (
 	// simNetPowLimit is the highest proof of work value a Litecoin block
 	// can have for the simulation test network.  It is the value 2^255 - 1.
 	simNetPowLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, 255), bigOne)
+
+	// mainSubsidyEras is the subsidy schedule of the main and test
+	// networks as defined by GetBlockValue of the reference
+	// implementation, which applies to both networks.  The genesis block
+	// pays the 50 coins of its hardcoded coinbase and block 1 the premine,
+	// after which the subsidy steps down at the end of given epochs of
+	// 36000 blocks until no subsidy is paid after block 26280000.
+	mainSubsidyEras = []SubsidyEra{
+		{Height: 0, Subsidy: 50 * 1e8},
+		{Height: 1, Subsidy: 10747 * 1e8},
+		{Height: 2, Subsidy: 1024 * 1e8},
+		{Height: 72001, Subsidy: 512 * 1e8},
+		{Height: 144001, Subsidy: 256 * 1e8},
+		{Height: 288001, Subsidy: 128 * 1e8},
+		{Height: 432001, Subsidy: 64 * 1e8},
+		{Height: 576001, Subsidy: 32 * 1e8},
+		{Height: 864001, Subsidy: 16 * 1e8},
+		{Height: 1080001, Subsidy: 8 * 1e8},
+		{Height: 1584001, Subsidy: 4 * 1e8},
+		{Height: 2304001, Subsidy: 2 * 1e8},
+		{Height: 5256001, Subsidy: 1 * 1e8},
+		{Height: 26280001, Subsidy: 0},
+	}
+
+	// mainSuperblockRules defines the wormholes of the main and test
+	// networks as defined by GetBlockValue of the reference
+	// implementation: 180 consecutive blocks paying 2973 coins in each
+	// epoch of 36000 blocks from the second to the 147th.
+	mainSuperblockRules = []SuperblockRule{
+		{
+			EpochLength: 36000,
+			FirstEpoch:  2,
+			LastEpoch:   147,
+			Length:      180,
+			SeedFactor:  5299860,
+			Subsidy:     2973 * 1e8,
+		},
+	}
 )
 
 // Checkpoint identifies a known good point in the block chain.  Using
@@ -49,8 +87,9 @@ var (
 // and also prevents forks from old blocks.
 //
 // Each checkpoint is selected based upon several factors.  See the
-// documentation for blockchain.IsCheckpointCandidate for details on the
-// selection criteria.
+// documentation for IsCheckpointCandidate for details on the selection
+// criteria.  The gencheckpoints.go program selects candidates from a dump of
+// the block headers of a network.
 type Checkpoint struct {
 	Height int32
 	Hash   *chainhash.Hash
@@ -68,23 +107,76 @@ type DNSSeed struct {
 
 // ConsensusDeployment defines details related to a specific consensus rule
 // change that is voted in.  This is part of BIP0009.
+//
+// Deployments are time based as defined by BIP0009 unless TimeoutHeight is
+// set, in which case they are height based as defined by BIP0008.  The zero
+// values of the BIP0008 and speedy trial fields keep the BIP0009 semantics.
 type ConsensusDeployment struct {
+	// Name uniquely identifies the deployment within a network.  See
+	// Params.Deployment.
+	Name string
+
+	// Description is a short human-readable description of the rule
+	// change.
+	Description string
+
+	// BIP references the BIPs which define the rule change, such as
+	// "BIP0141".
+	BIP string
+
 	// BitNumber defines the specific bit number within the block version
 	// this particular soft-fork deployment refers to.
 	BitNumber uint8
 
 	// StartTime is the median block time after which voting on the
-	// deployment starts.
+	// deployment starts.  It is ignored by height based deployments.
 	StartTime uint64
 
 	// ExpireTime is the median block time after which the attempted
-	// deployment expires.
+	// deployment expires.  It is ignored by height based deployments.
 	ExpireTime uint64
+
+	// StartHeight is the height of the first block of the first miner
+	// confirmation window in which voting on a height based deployment
+	// starts.  It requires TimeoutHeight.
+	StartHeight int32
+
+	// TimeoutHeight is the height of the first block of the miner
+	// confirmation window from which a height based deployment that has
+	// not locked in fails, or must signal when LockInOnTimeout is set.  A
+	// zero value makes the deployment time based.
+	TimeoutHeight int32
+
+	// MinActivationHeight is the lowest height at which a locked in
+	// deployment becomes active, as introduced by the speedy trial
+	// activation.  The deployment stays locked in until the first miner
+	// confirmation window which starts at or above this height.  Zero
+	// activates it in the window following the lock in.
+	MinActivationHeight int32
+
+	// CountBeforeTimeout makes a time based deployment count the votes of
+	// a miner confirmation window before considering ExpireTime, as
+	// introduced by the speedy trial activation, so a window which reaches
+	// the threshold locks in even though it ends past the expiration
+	// time.  Height based deployments always count the votes first.
+	CountBeforeTimeout bool
+
+	// LockInOnTimeout makes a height based deployment enter the must
+	// signal state in the last window before TimeoutHeight instead of
+	// failing, which forces it to lock in.  It requires TimeoutHeight.
+	LockInOnTimeout bool
 }
 
-// Constants that define the deployment offset in the deployments field of the
-// parameters for each deployment.  This is useful to be able to get the details
-// of a specific deployment by name.
+// IsHeightBased returns whether the deployment starts and times out at block
+// heights, as defined by BIP0008, instead of median block times.
+func (d *ConsensusDeployment) IsHeightBased() bool {
+	return d.TimeoutHeight != 0
+}
+
+// Constants that define the IDs of the deployments which were defined before
+// deployments were identified by name.  They are kept for compatibility and
+// refer to the deployments named by the DeploymentName constants.  See
+// DeploymentByID.
 const (
 	// DeploymentTestDummy defines the rule change deployment ID for testing
 	// purposes.
@@ -100,6 +192,11 @@ const (
 	// includes the deployment of BIPS 141, 142, 144, 145, 147 and 173.
 	DeploymentSegwit
 
+	// DeploymentTaproot defines the rule change deployment ID for the
+	// taproot soft-fork package.  The taproot package includes the
+	// deployment of BIPS 340, 341 and 342.
+	DeploymentTaproot
+
 	// NOTE: DefinedDeployments must always come last since it is used to
 	// determine how many defined deployments there currently are.
 
@@ -107,6 +204,25 @@ const (
 	DefinedDeployments
 )
 
+// These constants define the names of the deployments of the default networks.
+const (
+	// DeploymentNameTestDummy is the name of the rule change deployment
+	// used for testing purposes.
+	DeploymentNameTestDummy = "testdummy"
+
+	// DeploymentNameCSV is the name of the rule change deployment for the
+	// CSV soft-fork package.
+	DeploymentNameCSV = "csv"
+
+	// DeploymentNameSegwit is the name of the rule change deployment for
+	// the segwit soft-fork package.
+	DeploymentNameSegwit = "segwit"
+
+	// DeploymentNameTaproot is the name of the rule change deployment for
+	// the taproot soft-fork package.
+	DeploymentNameTaproot = "taproot"
+)
+
 // Params defines a Einsteinium network by its parameters.  These parameters may be
 // used by Einsteinium applications to differentiate networks as well as addresses
 // and keys for one network from those intended for use on another network.
@@ -118,12 +234,29 @@ type Params struct {
 	Net wire.BitcoinNet
 
 	// DefaultPort defines the default peer-to-peer port for the network.
+	// It is derived from P2PPort when the network is registered and is
+	// kept for compatibility.
 	DefaultPort string
 
+	// P2PPort defines the default peer-to-peer port for the network.
+	P2PPort uint16
+
+	// RPCPort defines the default port of the RPC server of the node for
+	// the network.
+	RPCPort uint16
+
+	// WalletRPCPort defines the default port of the RPC server of the
+	// wallet for the network.
+	WalletRPCPort uint16
+
 	// DNSSeeds defines a list of DNS seeds for the network that are used
 	// as one method to discover peers.
 	DNSSeeds []DNSSeed
 
+	// FixedSeeds defines a list of hard-coded node addresses used to
+	// bootstrap the network when the DNS seeds are not available.
+	FixedSeeds []SeedAddr
+
 	// GenesisBlock defines the first block of the chain.
 	GenesisBlock *wire.MsgBlock
 
@@ -139,19 +272,45 @@ type Params struct {
 	PowLimitBits uint32
 
 	// These fields define the block heights at which the specified softfork
-	// BIP became active.
+	// BIP became active.  They are part of the fork table of the network
+	// under the ForkBIP0034, ForkBIP0065 and ForkBIP0066 names.
 	BIP0034Height int32
 	BIP0065Height int32
 	BIP0066Height int32
 
+	// Forks defines additional consensus rule changes which activate at a
+	// fixed height, such as network specific hard forks.  A fork named
+	// after one of the BIP height fields above overrides it.  See
+	// IsActive.
+	Forks []Fork
+
 	// CoinbaseMaturity is the number of blocks required before newly mined
 	// coins (coinbase transactions) can be spent.
 	CoinbaseMaturity uint16
 
+	// CoinbaseMaturityEras optionally changes the coinbase maturity from
+	// given heights of the spending block onwards, ordered by height.  See
+	// CoinbaseMaturityAt.
+	CoinbaseMaturityEras []MaturityEra
+
 	// SubsidyReductionInterval is the interval of blocks before the subsidy
 	// is reduced.
 	SubsidyReductionInterval int32
 
+	// BaseSubsidy is the subsidy, in the smallest unit of the coin, paid
+	// for blocks before the first subsidy reduction.
+	BaseSubsidy int64
+
+	// SubsidyEras optionally defines the subsidy schedule as a list of
+	// eras ordered by height.  When set, it replaces the halving of
+	// BaseSubsidy every SubsidyReductionInterval blocks, which allows
+	// schedules that do not halve to be expressed.
+	SubsidyEras []SubsidyEra
+
+	// SuperblockRules defines the superblocks which pay a bonus subsidy
+	// instead of the base subsidy of their era.  See SuperblockRule.
+	SuperblockRules []SuperblockRule
+
 	// TargetTimespan is the desired amount of time that should elapse
 	// before the block difficulty requirement is examined to determine how
 	// it should be changed in order to maintain the desired block
@@ -167,6 +326,12 @@ type Params struct {
 	// difficulty retargets.
 	RetargetAdjustmentFactor int64
 
+	// RetargetAdjustmentFactorMin and RetargetAdjustmentFactorMax replace
+	// RetargetAdjustmentFactor with separate limits on how far the
+	// difficulty may rise and fall respectively in a single retarget.  The
+	// actual timespan is clamped to TargetTimespan divided by the former
+	// and TargetTimespan multiplied by the latter.  A zero value falls
+	// back to RetargetAdjustmentFactor.
 	RetargetAdjustmentFactorMin int64
 
 	RetargetAdjustmentFactorMax int64
@@ -183,12 +348,32 @@ type Params struct {
 	// NOTE: This only applies if ReduceMinDifficulty is true.
 	MinDiffReductionTime time.Duration
 
+	// DifficultyAlgorithms defines the algorithms used to calculate the
+	// required difficulty of blocks along with the height from which each
+	// of them is used, ordered by height.  The classic interval retarget
+	// is used for any height before the first entry.
+	DifficultyAlgorithms []DifficultyActivation
+
 	// GenerateSupported specifies whether or not CPU mining is allowed.
 	GenerateSupported bool
 
-	// Checkpoints ordered from oldest to newest.
+	// Checkpoints ordered from oldest to newest.  See LatestCheckpoint,
+	// CheckpointAt and VerifyCheckpoint.
 	Checkpoints []Checkpoint
 
+	// AssumeValid is the hash of a block whose ancestors are assumed to
+	// have valid scripts, which allows nodes to skip their script
+	// verification during the initial block download.  It must not be
+	// below the latest checkpoint.  Nil disables the optimization.  See
+	// IsAssumedValid.
+	AssumeValid *chainhash.Hash
+
+	// MinimumChainWork is the minimum amount of accumulated work a chain
+	// must have before it is trusted, which protects nodes from being fed
+	// a low work chain during the initial block download.  Nil disables
+	// the check.  See HasEnoughWork.
+	MinimumChainWork *big.Int
+
 	// These fields are related to voting on consensus rule changes as
 	// defined by BIP0009.
 	//
@@ -201,16 +386,17 @@ type Params struct {
 	// state retarget window.
 	//
 	// Deployments define the specific consensus rule changes to be voted
-	// on.
+	// on, each identified by a unique name.  See Deployment.
 	RuleChangeActivationThreshold uint32
 	MinerConfirmationWindow       uint32
-	Deployments                   [DefinedDeployments]ConsensusDeployment
+	Deployments                   []ConsensusDeployment
 
 	// Mempool parameters
 	RelayNonStdTxs bool
 
 	// Human-readable part for Bech32 encoded segwit addresses, as defined
-	// in BIP 173.
+	// in BIP 173.  It is shared by addresses of every witness version,
+	// which are encoded as described by SegwitChecksumVariant.
 	Bech32HRPSegwit string
 
 	// Address encoding magics
@@ -229,15 +415,33 @@ type Params struct {
 	HDCoinType uint32
 
 	// Einsteinium charity address
+	//
+	// CharityPubKey is the hex encoded public key coinbase transactions
+	// must pay the charity share of the block subsidy to.  See
+	// CharityScript.  It is only used when CharityKeys is empty.
 	CharityPubKey string
+
+	// CharityKeys defines the charity keys coinbase transactions must pay
+	// to along with the height from which each applies, ordered by
+	// height.  It allows the charity key to be rotated.  See
+	// CharityScriptAt.
+	CharityKeys []CharityKey
+
+	// CharityRates defines the percentage of the block subsidy owed to the
+	// charity along with the height from which it applies, ordered by
+	// height.
+	CharityRates []CharityRate
 }
 
 // MainNetParams defines the network parameters for the main Litecoin network.
 var MainNetParams = Params{
-	Name:        "mainnet",
-	Net:         wire.MainNet,
-	DefaultPort: "41888",
-	DNSSeeds: []DNSSeed{},
+	Name:          "mainnet",
+	Net:           wire.MainNet,
+	P2PPort:       41888,
+	RPCPort:       41889,
+	WalletRPCPort: 41887,
+	DNSSeeds:      []DNSSeed{},
+	FixedSeeds:    nil, // Unset until generated with genseeds.go.
 
 	// Chain parameters
 	GenesisBlock:             &genesisBlock,
@@ -258,33 +462,69 @@ var MainNetParams = Params{
 	MinDiffReductionTime:     0,
 	GenerateSupported:        false,
 
+	// Subsidy schedule.
+	SubsidyEras:     mainSubsidyEras,
+	SuperblockRules: mainSuperblockRules,
+
+	// Difficulty algorithms ordered by activation height.
+	DifficultyAlgorithms: []DifficultyActivation{
+		{Height: 0, Algorithm: ClassicRetarget{}},
+	},
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{
 		//{0, newHashFromStr("4e56204bb7b8ac06f860ff1c845f03f984303b5b97eb7b42868f714611aed94b")},
 	},
 
+	// Fast sync parameters.  They are deliberately left unset, which
+	// disables assume valid and the minimum chain work check, until they
+	// have been determined from a synced node of the main chain.
+	AssumeValid:      nil,
+	MinimumChainWork: nil,
+
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
 	//   target proof of work timespan / target proof of work spacing
 	RuleChangeActivationThreshold: 15120, // <-- Einsteinium: 75% of 20160
 	MinerConfirmationWindow:       20160, // <-- Einsteinium: approx. 2 weeks
-	Deployments: [DefinedDeployments]ConsensusDeployment{
-		DeploymentTestDummy: {
+	Deployments: []ConsensusDeployment{
+		{
+			Name:        DeploymentNameTestDummy,
+			Description: "Dummy deployment used for testing",
+			BIP:         "BIP0009",
+
 			BitNumber:  28,
 			StartTime:  1199145601, // January 1, 2008 UTC
 			ExpireTime: 1230767999, // December 31, 2008 UTC
 		},
-		DeploymentCSV: {
+		{
+			Name:        DeploymentNameCSV,
+			Description: "Relative lock-time using consensus-enforced sequence numbers",
+			BIP:         "BIP0068, BIP0112, BIP0113",
+
 			BitNumber:  0,
 			StartTime:  1485561600, // January 28, 2017 UTC
 			ExpireTime: 1517356801, // January 31st, 2018 UTC
 		},
-		DeploymentSegwit: {
+		{
+			Name:        DeploymentNameSegwit,
+			Description: "Segregated witness",
+			BIP:         "BIP0141, BIP0143, BIP0147",
+
 			BitNumber:  1,
 			StartTime:  1485561600, // January 28, 2017 UTC
 			ExpireTime: 1517356801, // January 31st, 2018 UTC.
 		},
+		{
+			Name:        DeploymentNameTaproot,
+			Description: "Schnorr signatures and taproot outputs",
+			BIP:         "BIP0340, BIP0341, BIP0342",
+
+			BitNumber:  2,
+			StartTime:  math.MaxInt64, // Not yet scheduled.
+			ExpireTime: math.MaxInt64, // Not yet scheduled.
+		},
 	},
 
 	// Mempool parameters
@@ -314,10 +554,13 @@ var MainNetParams = Params{
 // Litecoin network.  Not to be confused with the test Litecoin network (version
 // 3), this network is sometimes simply called "testnet".
 var RegressionNetParams = Params{
-	Name:        "regtest",
-	Net:         wire.TestNet,
-	DefaultPort: "31880",
-	DNSSeeds:    []DNSSeed{},
+	Name:          "regtest",
+	Net:           wire.TestNet,
+	P2PPort:       31880,
+	RPCPort:       31881,
+	WalletRPCPort: 31882,
+	DNSSeeds:      []DNSSeed{},
+	FixedSeeds:    nil,
 
 	// Chain parameters
 	GenesisBlock:             &regTestGenesisBlock,
@@ -329,6 +572,7 @@ var RegressionNetParams = Params{
 	BIP0065Height:            1351,      // Used by regression tests
 	BIP0066Height:            1251,      // Used by regression tests
 	SubsidyReductionInterval: 150,
+	BaseSubsidy:              50 * 1e8,          // 50 coins, 14999.9999835 in total
 	TargetTimespan:           time.Hour * 84, // 24*3.5 days
 	TargetTimePerBlock:       time.Second * 150,    // 2.5 minutes
 	RetargetAdjustmentFactor: 4,                   // 25% less, 400% more
@@ -336,31 +580,62 @@ var RegressionNetParams = Params{
 	MinDiffReductionTime:     time.Second * 150, // TargetTimePerBlock * 2
 	GenerateSupported:        true,
 
+	// Difficulty algorithms ordered by activation height.
+	DifficultyAlgorithms: []DifficultyActivation{
+		{Height: 0, Algorithm: ClassicRetarget{}},
+	},
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
 
+	// Fast sync parameters.  The regression test network does not use
+	// them.
+	AssumeValid:      nil,
+	MinimumChainWork: nil,
+
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
 	//   target proof of work timespan / target proof of work spacing
 	RuleChangeActivationThreshold: 108, // 75%  of MinerConfirmationWindow
 	MinerConfirmationWindow:       144,
-	Deployments: [DefinedDeployments]ConsensusDeployment{
-		DeploymentTestDummy: {
+	Deployments: []ConsensusDeployment{
+		{
+			Name:        DeploymentNameTestDummy,
+			Description: "Dummy deployment used for testing",
+			BIP:         "BIP0009",
+
 			BitNumber:  28,
 			StartTime:  0,             // Always available for vote
 			ExpireTime: math.MaxInt64, // Never expires
 		},
-		DeploymentCSV: {
+		{
+			Name:        DeploymentNameCSV,
+			Description: "Relative lock-time using consensus-enforced sequence numbers",
+			BIP:         "BIP0068, BIP0112, BIP0113",
+
 			BitNumber:  0,
 			StartTime:  0,             // Always available for vote
 			ExpireTime: math.MaxInt64, // Never expires
 		},
-		DeploymentSegwit: {
+		{
+			Name:        DeploymentNameSegwit,
+			Description: "Segregated witness",
+			BIP:         "BIP0141, BIP0143, BIP0147",
+
 			BitNumber:  1,
 			StartTime:  0,             // Always available for vote
 			ExpireTime: math.MaxInt64, // Never expires.
 		},
+		{
+			Name:        DeploymentNameTaproot,
+			Description: "Schnorr signatures and taproot outputs",
+			BIP:         "BIP0340, BIP0341, BIP0342",
+
+			BitNumber:  2,
+			StartTime:  0,             // Always available for vote
+			ExpireTime: math.MaxInt64, // Never expires.
+		},
 	},
 
 	// Mempool parameters
@@ -386,18 +661,22 @@ var RegressionNetParams = Params{
 	HDCoinType: 1,
 
 	CharityPubKey: "0377ba3117d776b40b49a910e869cd32adee4d33578f7bf52e1879ea739c9796ca",
+	CharityRates: []CharityRate{
+		{Height: 0, Percent: 2},
+	},
 }
 
 // TestNet4Params defines the network parameters for the test Litecoin network
 // (version 4).  Not to be confused with the regression test network, this
 // network is sometimes simply called "testnet".
 var TestNet4Params = Params{
-	Name:        "testnet4",
-	Net:         wire.TestNet4, //TODO
-	DefaultPort: "31878",
-	DNSSeeds: []DNSSeed{
-
-	},
+	Name:          "testnet4",
+	Net:           wire.TestNet4, //TODO
+	P2PPort:       31878,
+	RPCPort:       31879,
+	WalletRPCPort: 31877,
+	DNSSeeds:      []DNSSeed{},
+	FixedSeeds:    nil, // Unset until generated with genseeds.go.
 
 	// Chain parameters
 	GenesisBlock:             &testNet4GenesisBlock,
@@ -418,31 +697,67 @@ var TestNet4Params = Params{
 	MinDiffReductionTime:     0, // TargetTimePerBlock * 2
 	GenerateSupported:        false,
 
+	// Subsidy schedule.
+	SubsidyEras:     mainSubsidyEras,
+	SuperblockRules: mainSuperblockRules,
+
+	// Difficulty algorithms ordered by activation height.
+	DifficultyAlgorithms: []DifficultyActivation{
+		{Height: 0, Algorithm: ClassicRetarget{}},
+	},
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
 
+	// Fast sync parameters.  They are deliberately left unset, which
+	// disables assume valid and the minimum chain work check, until they
+	// have been determined from a synced node of the test network.
+	AssumeValid:      nil,
+	MinimumChainWork: nil,
+
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
 	//   target proof of work timespan / target proof of work spacing
 	RuleChangeActivationThreshold: 15, // 75% of MinerConfirmationWindow
 	MinerConfirmationWindow:       15,
-	Deployments: [DefinedDeployments]ConsensusDeployment{
-		DeploymentTestDummy: {
+	Deployments: []ConsensusDeployment{
+		{
+			Name:        DeploymentNameTestDummy,
+			Description: "Dummy deployment used for testing",
+			BIP:         "BIP0009",
+
 			BitNumber:  28,
 			StartTime:  1199145601, // January 1, 2008 UTC
 			ExpireTime: 1230767999, // December 31, 2008 UTC
 		},
-		DeploymentCSV: {
+		{
+			Name:        DeploymentNameCSV,
+			Description: "Relative lock-time using consensus-enforced sequence numbers",
+			BIP:         "BIP0068, BIP0112, BIP0113",
+
 			BitNumber:  0,
 			StartTime:  1483228800, // January 1, 2017
 			ExpireTime: 1546300800, // January 31st, 2018
 		},
-		DeploymentSegwit: {
+		{
+			Name:        DeploymentNameSegwit,
+			Description: "Segregated witness",
+			BIP:         "BIP0141, BIP0143, BIP0147",
+
 			BitNumber:  1,
 			StartTime:  1483228800, // January 1, 2017
 			ExpireTime: 1546300800, // January 31st, 2018
 		},
+		{
+			Name:        DeploymentNameTaproot,
+			Description: "Schnorr signatures and taproot outputs",
+			BIP:         "BIP0340, BIP0341, BIP0342",
+
+			BitNumber:  2,
+			StartTime:  math.MaxInt64, // Not yet scheduled.
+			ExpireTime: math.MaxInt64, // Not yet scheduled.
+		},
 	},
 
 	// Mempool parameters
@@ -476,10 +791,13 @@ var TestNet4Params = Params{
 // following normal discovery rules.  This is important as otherwise it would
 // just turn into another public testnet.
 var SimNetParams = Params{
-	Name:        "simnet",
-	Net:         wire.SimNet,
-	DefaultPort: "18555",
-	DNSSeeds:    []DNSSeed{}, // NOTE: There must NOT be any seeds.
+	Name:          "simnet",
+	Net:           wire.SimNet,
+	P2PPort:       18555,
+	RPCPort:       18556,
+	WalletRPCPort: 18554,
+	DNSSeeds:      []DNSSeed{}, // NOTE: There must NOT be any seeds.
+	FixedSeeds:    nil,         // NOTE: There must NOT be any seeds.
 
 	// Chain parameters
 	GenesisBlock:             &simNetGenesisBlock,
@@ -491,6 +809,7 @@ var SimNetParams = Params{
 	BIP0066Height:            0, // Always active on simnet
 	CoinbaseMaturity:         100,
 	SubsidyReductionInterval: 210000,
+	BaseSubsidy:              50 * 1e8,            // 50 coins, 20999999.9769 in total
 	TargetTimespan:           time.Hour * 24 * 14, // 14 days
 	TargetTimePerBlock:       time.Minute * 10,    // 10 minutes
 	RetargetAdjustmentFactor: 4,                   // 25% less, 400% more
@@ -498,31 +817,62 @@ var SimNetParams = Params{
 	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
 	GenerateSupported:        true,
 
+	// Difficulty algorithms ordered by activation height.
+	DifficultyAlgorithms: []DifficultyActivation{
+		{Height: 0, Algorithm: ClassicRetarget{}},
+	},
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
 
+	// Fast sync parameters.  The simulation test network does not use
+	// them.
+	AssumeValid:      nil,
+	MinimumChainWork: nil,
+
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
 	//   target proof of work timespan / target proof of work spacing
 	RuleChangeActivationThreshold: 75, // 75% of MinerConfirmationWindow
 	MinerConfirmationWindow:       100,
-	Deployments: [DefinedDeployments]ConsensusDeployment{
-		DeploymentTestDummy: {
+	Deployments: []ConsensusDeployment{
+		{
+			Name:        DeploymentNameTestDummy,
+			Description: "Dummy deployment used for testing",
+			BIP:         "BIP0009",
+
 			BitNumber:  28,
 			StartTime:  0,             // Always available for vote
 			ExpireTime: math.MaxInt64, // Never expires
 		},
-		DeploymentCSV: {
+		{
+			Name:        DeploymentNameCSV,
+			Description: "Relative lock-time using consensus-enforced sequence numbers",
+			BIP:         "BIP0068, BIP0112, BIP0113",
+
 			BitNumber:  0,
 			StartTime:  0,             // Always available for vote
 			ExpireTime: math.MaxInt64, // Never expires
 		},
-		DeploymentSegwit: {
+		{
+			Name:        DeploymentNameSegwit,
+			Description: "Segregated witness",
+			BIP:         "BIP0141, BIP0143, BIP0147",
+
 			BitNumber:  1,
 			StartTime:  0,             // Always available for vote
 			ExpireTime: math.MaxInt64, // Never expires.
 		},
+		{
+			Name:        DeploymentNameTaproot,
+			Description: "Schnorr signatures and taproot outputs",
+			BIP:         "BIP0340, BIP0341, BIP0342",
+
+			BitNumber:  2,
+			StartTime:  0,             // Always available for vote
+			ExpireTime: math.MaxInt64, // Never expires.
+		},
 	},
 
 	// Mempool parameters
@@ -561,7 +911,8 @@ var (
 )
 
 var (
-	registeredNets       = make(map[wire.BitcoinNet]struct{})
+	registeredNets       = make(map[wire.BitcoinNet]*Params)
+	registeredPorts      = make(map[uint16]struct{})
 	pubKeyHashAddrIDs    = make(map[byte]struct{})
 	scriptHashAddrIDs    = make(map[byte]struct{})
 	bech32SegwitPrefixes = make(map[string]struct{})
@@ -576,17 +927,36 @@ func (d DNSSeed) String() string {
 // Register registers the network parameters for a Litecoin network.  This may
 // error with ErrDuplicateNet if the network is already registered (either
 // due to a previous Register call, or the network being one of the default
-// networks).
+// networks), or with ErrDuplicatePort if one of its ports is already used by a
+// registered network.  The DefaultPort of the network is derived from its
+// P2PPort, or the other way around, when only one of them is set.
 //
 // Network parameters should be registered into this package by a main package
 // as early as possible.  Then, library packages may lookup networks or network
 // parameters based on inputs and work regardless of the network being standard
 // or not.
 func Register(params *Params) error {
+	// Check the parameters with their ports derived, but only derive the
+	// ports of the passed parameters once they are accepted.
+	derived := *params
+	derived.derivePorts()
+	if err := derived.Validate(); err != nil {
+		return err
+	}
 	if _, ok := registeredNets[params.Net]; ok {
 		return ErrDuplicateNet
 	}
-	registeredNets[params.Net] = struct{}{}
+	ports := derived.ports()
+	for _, port := range ports {
+		if _, ok := registeredPorts[port]; ok {
+			return ErrDuplicatePort
+		}
+	}
+	params.derivePorts()
+	registeredNets[params.Net] = params
+	for _, port := range ports {
+		registeredPorts[port] = struct{}{}
+	}
 	pubKeyHashAddrIDs[params.PubKeyHashAddrID] = struct{}{}
 	scriptHashAddrIDs[params.ScriptHashAddrID] = struct{}{}
 	hdPrivToPubKeyIDs[params.HDPrivateKeyID] = params.HDPublicKeyID[:]
@@ -597,6 +967,43 @@ func Register(params *Params) error {
 	return nil
 }
 
+// Validate performs sanity checks on the parameters which can not be expressed
+// by the type system, such as the ordering of height based schedules.  It is
+// called by Register so malformed networks are never registered.
+func (p *Params) Validate() error {
+	if err := p.validateDifficultyAlgorithms(); err != nil {
+		return err
+	}
+	if err := p.validateSubsidyEras(); err != nil {
+		return err
+	}
+	if err := p.validateForks(); err != nil {
+		return err
+	}
+	if err := p.validateDeployments(); err != nil {
+		return err
+	}
+	if err := p.validateCheckpoints(); err != nil {
+		return err
+	}
+	if err := p.validateAssumeValid(); err != nil {
+		return err
+	}
+	if err := p.validateCharityKeys(); err != nil {
+		return err
+	}
+	if err := p.validateCharityRates(); err != nil {
+		return err
+	}
+	if err := p.validateFixedSeeds(); err != nil {
+		return err
+	}
+	if err := p.validatePorts(); err != nil {
+		return err
+	}
+	return p.validateMaturityEras()
+}
+
 // mustRegister performs the same function as Register except it panics if there
 // is an error.  This should only be called from package init functions.
 func mustRegister(params *Params) {
@@ -0,0 +1,98 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrPortMismatch describes an error where the DefaultPort of a
+	// network is not the string form of its P2PPort.
+	ErrPortMismatch = errors.New("default port does not match the " +
+		"peer-to-peer port")
+
+	// ErrPortCollision describes an error where a network uses the same
+	// port for more than one service.
+	ErrPortCollision = errors.New("port used by more than one service")
+
+	// ErrDuplicatePort describes an error where the parameters for a
+	// network could not be registered due to one of its ports already
+	// being used by a registered network.
+	ErrDuplicatePort = errors.New("port already used by a registered " +
+		"network")
+)
+
+// ports returns the ports of the services of the network which are set.
+func (p *Params) ports() []uint16 {
+	var ports []uint16
+	for _, port := range []uint16{p.P2PPort, p.RPCPort, p.WalletRPCPort} {
+		if port != 0 {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// p2pPort returns the default peer-to-peer port of the network in string form.
+// It is P2PPort when set and the legacy DefaultPort otherwise, so parameters
+// which are not registered work with either of them.
+func (p *Params) p2pPort() string {
+	if p.P2PPort != 0 {
+		return strconv.Itoa(int(p.P2PPort))
+	}
+	return p.DefaultPort
+}
+
+// derivePorts fills in whichever of DefaultPort and P2PPort is not set from
+// the other one so parameters defining only one of them keep working.
+func (p *Params) derivePorts() {
+	switch {
+	case p.DefaultPort == "" && p.P2PPort != 0:
+		p.DefaultPort = strconv.Itoa(int(p.P2PPort))
+
+	case p.DefaultPort != "" && p.P2PPort == 0:
+		port, err := strconv.ParseUint(p.DefaultPort, 10, 16)
+		if err == nil {
+			p.P2PPort = uint16(port)
+		}
+	}
+}
+
+// validatePorts returns an error when both the DefaultPort and P2PPort of the
+// network are set but do not match, or when more than one service uses the
+// same port.
+func (p *Params) validatePorts() error {
+	if p.DefaultPort != "" && p.P2PPort != 0 {
+		if p.DefaultPort != strconv.Itoa(int(p.P2PPort)) {
+			return ErrPortMismatch
+		}
+	}
+
+	seen := make(map[uint16]struct{}, 3)
+	for _, port := range p.ports() {
+		if _, ok := seen[port]; ok {
+			return ErrPortCollision
+		}
+		seen[port] = struct{}{}
+	}
+	return nil
+}
+
+// JoinHostDefaultPort returns the passed host joined with the peer-to-peer
+// port of the passed network, P2PPort or DefaultPort when it is not set, unless
+// the host already specifies a port, in which case it is returned unchanged.
+// IPv6 addresses are enclosed in brackets whether they were passed with or
+// without them, and onion hostnames are joined like any other hostname.
+func JoinHostDefaultPort(host string, params *Params) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return net.JoinHostPort(host, params.p2pPort())
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// TestDefaultPortDerived ensures the DefaultPort of the default networks is
+// derived from their P2PPort.
+func TestDefaultPortDerived(t *testing.T) {
+	tests := []struct {
+		params *Params
+		want   string
+	}{
+		{&MainNetParams, "41888"},
+		{&TestNet4Params, "31878"},
+		{&RegressionNetParams, "31880"},
+		{&SimNetParams, "18555"},
+	}
+
+	for _, test := range tests {
+		if test.params.DefaultPort != test.want {
+			t.Errorf("%s: got default port %q, want %q",
+				test.params.Name, test.params.DefaultPort, test.want)
+		}
+	}
+}
+
+// TestValidatePorts ensures mismatched and colliding ports are rejected.
+func TestValidatePorts(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		err    error
+	}{
+		{
+			name:   "no ports",
+			params: Params{},
+		},
+		{
+			name:   "legacy default port only",
+			params: Params{DefaultPort: "9333"},
+		},
+		{
+			name: "matching ports",
+			params: Params{DefaultPort: "9333", P2PPort: 9333,
+				RPCPort: 9334, WalletRPCPort: 9332},
+		},
+		{
+			name:   "mismatched default port",
+			params: Params{DefaultPort: "9333", P2PPort: 9334},
+			err:    ErrPortMismatch,
+		},
+		{
+			name:   "rpc port collides with p2p port",
+			params: Params{P2PPort: 9333, RPCPort: 9333},
+			err:    ErrPortCollision,
+		},
+		{
+			name:   "wallet port collides with rpc port",
+			params: Params{P2PPort: 9333, RPCPort: 9334, WalletRPCPort: 9334},
+			err:    ErrPortCollision,
+		},
+	}
+
+	for _, test := range tests {
+		if err := test.params.validatePorts(); err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+// TestDerivePorts ensures whichever of DefaultPort and P2PPort is missing is
+// derived from the other one.
+func TestDerivePorts(t *testing.T) {
+	params := Params{P2PPort: 9333}
+	params.derivePorts()
+	if params.DefaultPort != "9333" {
+		t.Errorf("got default port %q, want %q", params.DefaultPort, "9333")
+	}
+
+	params = Params{DefaultPort: "9333"}
+	params.derivePorts()
+	if params.P2PPort != 9333 {
+		t.Errorf("got p2p port %d, want %d", params.P2PPort, 9333)
+	}
+}
+
+// TestRegisterDuplicatePort ensures a network using a port of a registered
+// network can not be registered.
+func TestRegisterDuplicatePort(t *testing.T) {
+	params := Params{
+		Name:               "portnet",
+		Net:                wire.BitcoinNet(0xfeedface),
+		TargetTimespan:     time.Hour,
+		TargetTimePerBlock: time.Minute,
+		P2PPort:            9999,
+		RPCPort:            MainNetParams.RPCPort,
+	}
+	if err := Register(&params); err != ErrDuplicatePort {
+		t.Fatalf("Register: got %v, want %v", err, ErrDuplicatePort)
+	}
+
+	// The failed registration must not have registered the network or
+	// its other ports.
+	if _, ok := registeredNets[params.Net]; ok {
+		t.Error("network registered despite the duplicate port")
+	}
+	if _, ok := registeredPorts[params.P2PPort]; ok {
+		t.Error("port registered despite the duplicate port")
+	}
+	if params.DefaultPort != "" {
+		t.Errorf("rejected parameters modified: got default port %q",
+			params.DefaultPort)
+	}
+}
+
+// TestJoinHostDefaultPort ensures the default port is only added to hosts
+// without a port and IPv6 addresses are bracketed.
+func TestJoinHostDefaultPort(t *testing.T) {
+	const onion = "2gzyxa5ihm7nsggfxnu52rck2vv4rvmdlkiu3zzui5du4xyclen53wid.onion"
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"1.2.3.4", "1.2.3.4:41888"},
+		{"1.2.3.4:8333", "1.2.3.4:8333"},
+		{"seed.example.test", "seed.example.test:41888"},
+		{"2001:db8::1", "[2001:db8::1]:41888"},
+		{"[2001:db8::1]", "[2001:db8::1]:41888"},
+		{"[2001:db8::1]:8333", "[2001:db8::1]:8333"},
+		{"::1", "[::1]:41888"},
+		{onion, onion + ":41888"},
+		{onion + ":8333", onion + ":8333"},
+	}
+
+	for _, test := range tests {
+		got := JoinHostDefaultPort(test.host, &MainNetParams)
+		if got != test.want {
+			t.Errorf("%q: got %q, want %q", test.host, got, test.want)
+		}
+	}
+
+	// Unregistered parameters may only set either port.
+	legacy := &Params{DefaultPort: "9333"}
+	if got := JoinHostDefaultPort("1.2.3.4", legacy); got != "1.2.3.4:9333" {
+		t.Errorf("legacy default port: got %q, want %q", got,
+			"1.2.3.4:9333")
+	}
+	typed := &Params{P2PPort: 9333}
+	if got := JoinHostDefaultPort("1.2.3.4", typed); got != "1.2.3.4:9333" {
+		t.Errorf("typed port: got %q, want %q", got, "1.2.3.4:9333")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/ltcsuite/ltcd/chaincfg"
 )
@@ -20,6 +21,9 @@ var mockNetParams = Params{
 	Bech32HRPSegwit:  "tc",
 	HDPrivateKeyID:   [4]byte{0x01, 0x02, 0x03, 0x04},
 	HDPublicKeyID:    [4]byte{0x05, 0x06, 0x07, 0x08},
+
+	TargetTimespan:     time.Hour,
+	TargetTimePerBlock: time.Minute,
 }
 
 func TestRegister(t *testing.T) {
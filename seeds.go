@@ -0,0 +1,140 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// DefaultSeedTimeout is the default time allowed for the lookup of a single
+// DNS seed.
+const DefaultSeedTimeout = 10 * time.Second
+
+// SeedHost returns the hostname to query for the DNS seed in order to find
+// nodes supporting the passed service flags.  Seeds with filtering support are
+// queried through the x<hex flags> subdomain, unless only SFNodeNetwork is
+// requested as that is what every seed returns by default.
+func (d DNSSeed) SeedHost(reqServices wire.ServiceFlag) string {
+	if !d.HasFiltering || reqServices == wire.SFNodeNetwork {
+		return d.Host
+	}
+	return fmt.Sprintf("x%x.%s", uint64(reqServices), d.Host)
+}
+
+// SeedResolver looks up the addresses of nodes through the DNS seeds of a
+// network.
+type SeedResolver struct {
+	// Params are the parameters of the network to find nodes for.  The
+	// DNSSeeds are queried and the P2PPort, or DefaultPort when it is not
+	// set, is applied to the results.
+	Params *Params
+
+	// Timeout is the time allowed for the lookup of a single seed.
+	// DefaultSeedTimeout is used when it is zero.
+	Timeout time.Duration
+
+	// Dial, when set, replaces the connection to the DNS server used by
+	// the lookups.  The address passed to it is the one of the system DNS
+	// server, which may be ignored in order to redirect queries to another
+	// server.  The system resolver is used when it is nil.
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// NewSeedResolver returns a SeedResolver for the passed network parameters
+// using the system resolver and DefaultSeedTimeout.
+func NewSeedResolver(params *Params) *SeedResolver {
+	return &SeedResolver{Params: params, Timeout: DefaultSeedTimeout}
+}
+
+// resolver returns the net.Resolver used to look up the seeds.
+func (r *SeedResolver) resolver() *net.Resolver {
+	if r.Dial == nil {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{PreferGo: true, Dial: r.Dial}
+}
+
+// lookup returns the IP addresses the passed seed returns for nodes supporting
+// the passed service flags.
+func (r *SeedResolver) lookup(ctx context.Context, resolver *net.Resolver,
+	seed DNSSeed, reqServices wire.ServiceFlag) ([]net.IP, error) {
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSeedTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addrs, err := resolver.LookupIPAddr(ctx, seed.SeedHost(reqServices))
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+	}
+	return ips, nil
+}
+
+// Resolve queries every DNS seed of the network concurrently for nodes
+// supporting the passed service flags and returns their addresses, with the
+// default port of the network, as host:port strings.  Addresses are returned
+// in the order of the seeds that returned them and duplicates are removed.
+//
+// A seed that fails or times out does not prevent the results of the others
+// from being returned.  An error is only returned when every seed failed, in
+// which case it is the error of the first seed.
+func (r *SeedResolver) Resolve(ctx context.Context,
+	reqServices wire.ServiceFlag) ([]string, error) {
+
+	seeds := r.Params.DNSSeeds
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+
+	resolver := r.resolver()
+	results := make([][]net.IP, len(seeds))
+	errs := make([]error, len(seeds))
+	var wg sync.WaitGroup
+	for i, seed := range seeds {
+		wg.Add(1)
+		go func(i int, seed DNSSeed) {
+			defer wg.Done()
+			results[i], errs[i] = r.lookup(ctx, resolver, seed,
+				reqServices)
+		}(i, seed)
+	}
+	wg.Wait()
+
+	var addrs []string
+	port := r.Params.p2pPort()
+	seen := make(map[string]struct{})
+	failed := 0
+	for i, ips := range results {
+		if errs[i] != nil {
+			failed++
+			continue
+		}
+		for _, ip := range ips {
+			addr := net.JoinHostPort(ip.String(), port)
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+			seen[addr] = struct{}{}
+			addrs = append(addrs, addr)
+		}
+	}
+	if failed == len(seeds) {
+		return nil, errs[0]
+	}
+	return addrs, nil
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// testDNSServer is a minimal in-process DNS server answering A and AAAA
+// queries over UDP from a static set of records.  Names without records are
+// answered with NXDOMAIN, while names in silent are never answered.
+type testDNSServer struct {
+	conn    net.PacketConn
+	records map[string][]net.IP
+	silent  map[string]bool
+}
+
+// newTestDNSServer starts a testDNSServer on the loopback interface.
+func newTestDNSServer(t *testing.T, records map[string][]net.IP,
+	silent map[string]bool) *testDNSServer {
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	s := &testDNSServer{conn: conn, records: records, silent: silent}
+	t.Cleanup(func() { conn.Close() })
+	go s.serve()
+	return s
+}
+
+// dial connects to the server regardless of the requested address.
+func (s *testDNSServer) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "udp", s.conn.LocalAddr().String())
+}
+
+// serve answers queries until the server is closed.
+func (s *testDNSServer) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if resp := s.answer(buf[:n]); resp != nil {
+			s.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+// answer returns the response to the passed query, or nil when it must not be
+// answered.
+func (s *testDNSServer) answer(query []byte) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	// Parse the name of the first question.
+	var labels []string
+	off := 12
+	for off < len(query) && query[off] != 0 {
+		l := int(query[off])
+		if off+1+l > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[off+1:off+1+l]))
+		off += 1 + l
+	}
+	off++
+	if off+4 > len(query) {
+		return nil
+	}
+	name := strings.ToLower(strings.Join(labels, "."))
+	qtype := binary.BigEndian.Uint16(query[off:])
+	question := query[12 : off+4]
+	if s.silent[name] {
+		return nil
+	}
+
+	var answers []net.IP
+	ips, ok := s.records[name]
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil && qtype == 1 {
+			answers = append(answers, ip4)
+		} else if ip4 == nil && qtype == 28 {
+			answers = append(answers, ip.To16())
+		}
+	}
+
+	// Authoritative answer with recursion available, NXDOMAIN for unknown
+	// names.
+	flags := uint16(0x8580)
+	if !ok {
+		flags |= 3
+	}
+	resp := make([]byte, 12, 512)
+	copy(resp, query[:2])
+	binary.BigEndian.PutUint16(resp[2:], flags)
+	binary.BigEndian.PutUint16(resp[4:], 1)
+	binary.BigEndian.PutUint16(resp[6:], uint16(len(answers)))
+	resp = append(resp, question...)
+	for _, ip := range answers {
+		rr := []byte{0xc0, 0x0c, 0, 0, 0, 1, 0, 0, 0, 60, 0, 0}
+		binary.BigEndian.PutUint16(rr[2:], qtype)
+		binary.BigEndian.PutUint16(rr[10:], uint16(len(ip)))
+		resp = append(resp, rr...)
+		resp = append(resp, ip...)
+	}
+	return resp
+}
+
+// TestSeedHost ensures the hostname queried for a seed only uses the service
+// flag subdomain when the seed supports filtering.
+func TestSeedHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		seed     DNSSeed
+		services wire.ServiceFlag
+		want     string
+	}{
+		{
+			name:     "no filtering",
+			seed:     DNSSeed{"seed.example.test", false},
+			services: wire.SFNodeNetwork | wire.SFNodeWitness,
+			want:     "seed.example.test",
+		},
+		{
+			name:     "filtering with default services",
+			seed:     DNSSeed{"seed.example.test", true},
+			services: wire.SFNodeNetwork,
+			want:     "seed.example.test",
+		},
+		{
+			name:     "filtering",
+			seed:     DNSSeed{"seed.example.test", true},
+			services: wire.SFNodeNetwork | wire.SFNodeWitness,
+			want:     "x9.seed.example.test",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.seed.SeedHost(test.services); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// TestSeedResolver ensures the seed resolver queries the expected hostnames,
+// deduplicates the results, applies the default port and does not let a
+// failing or unresponsive seed prevent the results of the others.
+func TestSeedResolver(t *testing.T) {
+	server := newTestDNSServer(t, map[string][]net.IP{
+		"plain.example.test": {
+			net.ParseIP("10.0.0.1"),
+			net.ParseIP("10.0.0.2"),
+		},
+		"x9.filter.example.test": {
+			net.ParseIP("10.0.0.2"),
+			net.ParseIP("2001:db8::1"),
+		},
+		"filter.example.test": {net.ParseIP("10.0.0.9")},
+	}, map[string]bool{"slow.example.test": true})
+
+	params := &Params{
+		P2PPort: 9333,
+		DNSSeeds: []DNSSeed{
+			{"plain.example.test", false},
+			{"missing.example.test", false},
+			{"slow.example.test", false},
+			{"filter.example.test", true},
+		},
+	}
+	r := NewSeedResolver(params)
+	r.Timeout = 500 * time.Millisecond
+	r.Dial = server.dial
+
+	addrs, err := r.Resolve(context.Background(),
+		wire.SFNodeNetwork|wire.SFNodeWitness)
+	if err != nil {
+		t.Fatalf("Resolve: unexpected error: %v", err)
+	}
+	want := []string{
+		"10.0.0.1:9333",
+		"10.0.0.2:9333",
+		"[2001:db8::1]:9333",
+	}
+	if !reflect.DeepEqual(addrs, want) {
+		t.Fatalf("Resolve: got %v, want %v", addrs, want)
+	}
+
+	// The unfiltered hostname is queried for the default services.
+	addrs, err = r.Resolve(context.Background(), wire.SFNodeNetwork)
+	if err != nil {
+		t.Fatalf("Resolve: unexpected error: %v", err)
+	}
+	want = []string{"10.0.0.1:9333", "10.0.0.2:9333", "10.0.0.9:9333"}
+	if !reflect.DeepEqual(addrs, want) {
+		t.Fatalf("Resolve: got %v, want %v", addrs, want)
+	}
+
+	// An error is returned when every seed fails.
+	params.DNSSeeds = []DNSSeed{{"missing.example.test", false}}
+	if _, err := r.Resolve(context.Background(), wire.SFNodeNetwork); err == nil {
+		t.Fatal("Resolve: expected error when every seed fails")
+	}
+
+	// No seeds is not an error.
+	params.DNSSeeds = nil
+	addrs, err = r.Resolve(context.Background(), wire.SFNodeNetwork)
+	if err != nil || addrs != nil {
+		t.Fatalf("Resolve: got %v, %v, want no addresses", addrs, err)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"strings"
+)
+
+// ChecksumVariant identifies the checksum used by a bech32 encoded string.
+type ChecksumVariant int
+
+// These constants define the checksum variants of bech32 encoded strings.
+const (
+	// Bech32 is the checksum defined by BIP0173.  It is used by version 0
+	// witness programs.
+	Bech32 ChecksumVariant = iota + 1
+
+	// Bech32m is the checksum defined by BIP0350.  It is used by witness
+	// programs of version 1 and higher.
+	Bech32m
+)
+
+// String returns the ChecksumVariant as a human-readable name.
+func (v ChecksumVariant) String() string {
+	switch v {
+	case Bech32:
+		return "bech32"
+	case Bech32m:
+		return "bech32m"
+	}
+	return "unknown"
+}
+
+// These constants define the limits on the witness programs of segwit
+// addresses.
+const (
+	// maxWitnessVersion is the highest witness version.
+	maxWitnessVersion = 16
+
+	// minWitnessProgramLen and maxWitnessProgramLen are the minimum and
+	// maximum lengths of a witness program.
+	minWitnessProgramLen = 2
+	maxWitnessProgramLen = 40
+
+	// maxBech32Len is the maximum length of a bech32 encoded segwit
+	// address.
+	maxBech32Len = 90
+
+	// bech32ChecksumLen is the number of characters of the checksum.
+	bech32ChecksumLen = 6
+)
+
+// These constants are the values the bech32 checksum polynomial must produce
+// for valid strings of each checksum variant.
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// bech32Charset is the set of characters used by the data part of bech32
+// encoded strings.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var (
+	// ErrInvalidBech32 describes an error where a segwit address is not a
+	// well formed bech32 string, for example due to a bad checksum,
+	// invalid characters or mixed case.
+	ErrInvalidBech32 = errors.New("invalid bech32 string")
+
+	// ErrWrongSegwitHRP describes an error where the human-readable part
+	// of a segwit address is not the one of the network.
+	ErrWrongSegwitHRP = errors.New("segwit address is for another network")
+
+	// ErrInvalidWitnessVersion describes an error where a segwit address
+	// encodes a witness version above 16.
+	ErrInvalidWitnessVersion = errors.New("invalid witness version")
+
+	// ErrInvalidWitnessProgram describes an error where the witness
+	// program of a segwit address has an invalid length for its witness
+	// version or is not padded correctly.
+	ErrInvalidWitnessProgram = errors.New("invalid witness program")
+
+	// ErrWrongChecksumVariant describes an error where a segwit address
+	// uses the bech32 checksum variant which does not match its witness
+	// version.
+	ErrWrongChecksumVariant = errors.New("wrong checksum variant for " +
+		"witness version")
+)
+
+// SegwitChecksumVariant returns the checksum variant segwit addresses paying to
+// witness programs of the passed version must be encoded with: bech32 for
+// version 0 and bech32m for versions 1 through 16, as defined by BIP0350.
+// ErrInvalidWitnessVersion is returned for higher versions.
+func (p *Params) SegwitChecksumVariant(witnessVersion byte) (ChecksumVariant, error) {
+	switch {
+	case witnessVersion == 0:
+		return Bech32, nil
+	case witnessVersion <= maxWitnessVersion:
+		return Bech32m, nil
+	}
+	return 0, ErrInvalidWitnessVersion
+}
+
+// bech32Polymod computes the bech32 checksum polynomial of the passed values.
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd,
+		0x2a1462b3}
+
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// decodeBech32 decodes the passed bech32 string into its human-readable part
+// and data, without the checksum, and returns the checksum variant it uses.
+func decodeBech32(s string) (string, []byte, ChecksumVariant, error) {
+	if len(s) > maxBech32Len {
+		return "", nil, 0, ErrInvalidBech32
+	}
+
+	// Only printable ASCII characters are allowed and the string must not
+	// mix upper and lower case.
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", nil, 0, ErrInvalidBech32
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 126 {
+			return "", nil, 0, ErrInvalidBech32
+		}
+	}
+
+	// The human-readable part is separated from the data by the last '1'
+	// and both must be present.
+	sep := strings.LastIndexByte(lower, '1')
+	if sep < 1 || sep+bech32ChecksumLen+1 > len(lower) {
+		return "", nil, 0, ErrInvalidBech32
+	}
+	hrp := lower[:sep]
+
+	data := make([]byte, 0, len(lower)-sep-1)
+	for i := sep + 1; i < len(lower); i++ {
+		v := strings.IndexByte(bech32Charset, lower[i])
+		if v < 0 {
+			return "", nil, 0, ErrInvalidBech32
+		}
+		data = append(data, byte(v))
+	}
+
+	// Verify the checksum over the expanded human-readable part and data.
+	values := make([]byte, 0, 2*len(hrp)+1+len(data))
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+	values = append(values, data...)
+
+	var variant ChecksumVariant
+	switch bech32Polymod(values) {
+	case bech32Const:
+		variant = Bech32
+	case bech32mConst:
+		variant = Bech32m
+	default:
+		return "", nil, 0, ErrInvalidBech32
+	}
+
+	return hrp, data[:len(data)-bech32ChecksumLen], variant, nil
+}
+
+// convertBits regroups the passed 5-bit groups into bytes.  Incomplete groups
+// at the end must be zero padding of less than five bits.
+func convertBits(data []byte) ([]byte, bool) {
+	var acc uint32
+	var bits uint
+	out := make([]byte, 0, len(data)*5/8)
+	for _, v := range data {
+		acc = acc<<5 | uint32(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			out = append(out, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+// ValidateSegwitAddress ensures the passed string is a segwit address for the
+// network as defined by BIP0173 and BIP0350.  The human-readable part must be
+// Bech32HRPSegwit, the witness version at most 16 and the witness program
+// between 2 and 40 bytes long, or exactly 20 or 32 bytes for version 0.
+// Version 0 addresses must use the bech32 checksum and later versions the
+// bech32m checksum.
+func (p *Params) ValidateSegwitAddress(addr string) error {
+	hrp, data, variant, err := decodeBech32(addr)
+	if err != nil {
+		return err
+	}
+	if hrp != strings.ToLower(p.Bech32HRPSegwit) {
+		return ErrWrongSegwitHRP
+	}
+
+	// The first 5-bit group is the witness version and the rest is the
+	// witness program.
+	if len(data) == 0 {
+		return ErrInvalidWitnessProgram
+	}
+	witnessVersion := data[0]
+	wantVariant, err := p.SegwitChecksumVariant(witnessVersion)
+	if err != nil {
+		return err
+	}
+
+	program, ok := convertBits(data[1:])
+	if !ok || len(program) < minWitnessProgramLen ||
+		len(program) > maxWitnessProgramLen {
+
+		return ErrInvalidWitnessProgram
+	}
+	if witnessVersion == 0 && len(program) != 20 && len(program) != 32 {
+		return ErrInvalidWitnessProgram
+	}
+
+	if variant != wantVariant {
+		return ErrWrongChecksumVariant
+	}
+
+	return nil
+}
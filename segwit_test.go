@@ -0,0 +1,117 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import "testing"
+
+// TestSegwitChecksumVariant ensures version 0 witness programs use bech32 and
+// later versions use bech32m.
+func TestSegwitChecksumVariant(t *testing.T) {
+	tests := []struct {
+		version byte
+		want    ChecksumVariant
+		err     error
+	}{
+		{0, Bech32, nil},
+		{1, Bech32m, nil},
+		{16, Bech32m, nil},
+		{17, 0, ErrInvalidWitnessVersion},
+	}
+
+	for _, test := range tests {
+		variant, err := MainNetParams.SegwitChecksumVariant(test.version)
+		if variant != test.want || err != test.err {
+			t.Errorf("SegwitChecksumVariant(%d) = %v, %v, want %v, %v",
+				test.version, variant, err, test.want, test.err)
+		}
+	}
+}
+
+// TestValidateSegwitAddress ensures segwit addresses are validated against the
+// BIP0173 and BIP0350 test vectors.
+func TestValidateSegwitAddress(t *testing.T) {
+	mainNet := &Params{Bech32HRPSegwit: "bc"}
+	testNet := &Params{Bech32HRPSegwit: "tb"}
+
+	tests := []struct {
+		params *Params
+		addr   string
+		err    error
+	}{
+		// Valid addresses.
+		{mainNet, "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", nil},
+		{testNet, "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpy" +
+			"sxf3q0sl5k7", nil},
+		{mainNet, "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r" +
+			"3zarvary0c5xw7kt5nd6y", nil},
+		{mainNet, "BC1SW50QGDZ25J", nil},
+		{mainNet, "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", nil},
+		{mainNet, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz" +
+			"7vqzk5jj0", nil},
+
+		// Invalid addresses.
+		{mainNet, "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpy" +
+			"sxf3q0sl5k7", ErrWrongSegwitHRP},
+		{testNet, "tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz" +
+			"7vq5zuyut", ErrWrongSegwitHRP},
+		{mainNet, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz" +
+			"7vqh2y7hd", ErrWrongChecksumVariant},
+		{mainNet, "BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ" +
+			"7VQ54WELL", ErrWrongChecksumVariant},
+		{mainNet, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh",
+			ErrWrongChecksumVariant},
+		{mainNet, "bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995v" +
+			"vpql3jow4", ErrInvalidBech32},
+		{mainNet, "BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ" +
+			"7VQ7ZWS8R", ErrInvalidWitnessVersion},
+		{mainNet, "bc1pw5dgrnzv", ErrInvalidWitnessProgram},
+		{mainNet, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz" +
+			"7v8n0nx0muaewav253zgeav", ErrInvalidWitnessProgram},
+		{mainNet, "BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P",
+			ErrInvalidWitnessProgram},
+		{testNet, "tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz" +
+			"7vq47Zagq", ErrInvalidBech32},
+		{mainNet, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz" +
+			"7v07qwwzcrf", ErrInvalidWitnessProgram},
+		{testNet, "tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz" +
+			"7vpggkg4j", ErrInvalidWitnessProgram},
+		{mainNet, "bc1gmk9yu", ErrInvalidWitnessProgram},
+		{mainNet, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5",
+			ErrInvalidBech32},
+	}
+
+	for _, test := range tests {
+		err := test.params.ValidateSegwitAddress(test.addr)
+		if err != test.err {
+			t.Errorf("ValidateSegwitAddress(%q) = %v, want %v",
+				test.addr, err, test.err)
+		}
+	}
+}
+
+// TestTaprootDeployment ensures every default network defines the taproot
+// deployment.
+func TestTaprootDeployment(t *testing.T) {
+	networks := []*Params{
+		&MainNetParams,
+		&RegressionNetParams,
+		&TestNet4Params,
+		&SimNetParams,
+	}
+
+	for _, params := range networks {
+		deployment, err := params.DeploymentByID(DeploymentTaproot)
+		if err != nil {
+			t.Errorf("%s: no taproot deployment: %v", params.Name, err)
+			continue
+		}
+		if deployment.Name != DeploymentNameTaproot ||
+			deployment.BitNumber != 2 {
+
+			t.Errorf("%s: unexpected taproot deployment %+v",
+				params.Name, deployment)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var (
+	// ErrSubsidyErasNotAscending describes an error where the heights of
+	// the subsidy eras of a network are not strictly ascending.
+	ErrSubsidyErasNotAscending = errors.New("subsidy era heights are not " +
+		"strictly ascending")
+
+	// ErrNegativeSubsidy describes an error where a subsidy era or a
+	// superblock rule of a network pays a negative subsidy.
+	ErrNegativeSubsidy = errors.New("negative subsidy")
+
+	// ErrInvalidSuperblockRule describes an error where a superblock rule
+	// of a network can not place its superblocks within its epochs, such
+	// as when they do not fit in an epoch.
+	ErrInvalidSuperblockRule = errors.New("invalid superblock rule")
+)
+
+// SubsidyEra defines the block subsidy paid from a given height onwards.
+type SubsidyEra struct {
+	// Height is the height of the first block of the era.
+	Height int32
+
+	// Subsidy is the subsidy, in the smallest unit of the coin, paid for
+	// every block of the era.
+	Subsidy int64
+}
+
+// SuperblockRule defines the superblocks, called wormholes by Einsteinium,
+// which pay a bonus subsidy instead of the base subsidy of their era.
+//
+// Heights are grouped into epochs of EpochLength blocks, where epoch n covers
+// the heights above (n-1)*EpochLength up to and including n*EpochLength.  In
+// every epoch from FirstEpoch to LastEpoch, Length consecutive blocks are
+// superblocks.  The first of them is the block at the offset from the start of
+// the epoch which the reference implementation draws between 1 and
+// EpochLength-Length with boost::uniform_int from a 32-bit Mersenne Twister
+// seeded with SeedFactor times the epoch, so superblocks only depend on the
+// height.
+type SuperblockRule struct {
+	// EpochLength is the number of blocks in an epoch.
+	EpochLength int32
+
+	// FirstEpoch and LastEpoch define the range of epochs, both
+	// inclusive, which contain superblocks.
+	FirstEpoch int32
+	LastEpoch  int32
+
+	// Length is the number of consecutive superblocks in an epoch.
+	Length int32
+
+	// SeedFactor is multiplied by the epoch to seed the random offset of
+	// the superblocks of the epoch.
+	SeedFactor uint32
+
+	// Subsidy is the subsidy, in the smallest unit of the coin, paid to a
+	// superblock.
+	Subsidy int64
+}
+
+// epoch returns the epoch of the rule the passed height belongs to.  The
+// genesis block is the only block of epoch zero.
+func (r *SuperblockRule) epoch(height int32) int32 {
+	epoch := height / r.EpochLength
+	if height%r.EpochLength != 0 {
+		epoch++
+	}
+	return epoch
+}
+
+// firstSuperblock returns the height of the first superblock of the passed
+// epoch.
+func (r *SuperblockRule) firstSuperblock(epoch int32) int32 {
+	gen := newMT19937(r.SeedFactor * uint32(epoch))
+	offset := gen.uniformInt(1, uint32(r.EpochLength-r.Length))
+	return int32(offset) + (epoch-1)*r.EpochLength
+}
+
+// isSuperblock returns whether the block at the passed height is a superblock
+// under the rule.
+func (r *SuperblockRule) isSuperblock(height int32) bool {
+	epoch := r.epoch(height)
+	if height <= 0 || epoch < r.FirstEpoch || epoch > r.LastEpoch {
+		return false
+	}
+	first := r.firstSuperblock(epoch)
+	return height >= first && height-first < r.Length
+}
+
+// superblockRuleAt returns the superblock rule under which the block at the
+// passed height is a superblock, or nil when it is not a superblock.
+func (p *Params) superblockRuleAt(height int32) *SuperblockRule {
+	for i := range p.SuperblockRules {
+		rule := &p.SuperblockRules[i]
+		if rule.isSuperblock(height) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// IsSuperblock returns whether the block at the passed height is a superblock
+// under the SuperblockRules of the network.
+func (p *Params) IsSuperblock(height int32) bool {
+	return p.superblockRuleAt(height) != nil
+}
+
+// BlockSubsidy returns the subsidy, in the smallest unit of the coin, paid to
+// the coinbase of the block at the passed height.
+//
+// Superblocks, as determined by IsSuperblock, are paid the subsidy of their
+// superblock rule.  Every other block is paid the base subsidy of its era:
+// when SubsidyEras is set, the subsidy is that of the era the height falls in
+// and zero before the first era, otherwise BaseSubsidy is halved every
+// SubsidyReductionInterval blocks.
+func (p *Params) BlockSubsidy(height int32) int64 {
+	if rule := p.superblockRuleAt(height); rule != nil {
+		return rule.Subsidy
+	}
+	return p.baseSubsidy(height)
+}
+
+// baseSubsidy returns the subsidy paid to a block at the passed height which
+// is not a superblock.  See BlockSubsidy for details.
+func (p *Params) baseSubsidy(height int32) int64 {
+	if height < 0 {
+		return 0
+	}
+
+	if len(p.SubsidyEras) > 0 {
+		// Find the first era which starts above the height.  The one
+		// right before it, if any, is the era of the block.
+		eras := p.SubsidyEras
+		i := sort.Search(len(eras), func(i int) bool {
+			return eras[i].Height > height
+		})
+		if i == 0 {
+			return 0
+		}
+		return eras[i-1].Subsidy
+	}
+
+	if p.SubsidyReductionInterval == 0 {
+		return p.BaseSubsidy
+	}
+
+	// Equivalent to: BaseSubsidy / 2^(height/SubsidyReductionInterval)
+	halvings := uint(height / p.SubsidyReductionInterval)
+	if halvings >= 64 {
+		return 0
+	}
+	return p.BaseSubsidy >> halvings
+}
+
+// subsidyEras returns the eras of the subsidy schedule of the network.  The
+// halving schedule is expanded into one era per halving until the subsidy
+// reaches zero or the heights are exhausted.
+func (p *Params) subsidyEras() []SubsidyEra {
+	if len(p.SubsidyEras) > 0 {
+		return p.SubsidyEras
+	}
+
+	if p.SubsidyReductionInterval == 0 {
+		return []SubsidyEra{{Height: 0, Subsidy: p.BaseSubsidy}}
+	}
+
+	var eras []SubsidyEra
+	for halvings := uint(0); halvings < 64; halvings++ {
+		height := int64(halvings) * int64(p.SubsidyReductionInterval)
+		if height > math.MaxInt32 {
+			break
+		}
+		subsidy := p.BaseSubsidy >> halvings
+		eras = append(eras, SubsidyEra{
+			Height:  int32(height),
+			Subsidy: subsidy,
+		})
+		if subsidy == 0 {
+			break
+		}
+	}
+	return eras
+}
+
+// TotalSupplyAt returns the total amount, in the smallest unit of the coin,
+// created by block subsidies, including superblocks, from the genesis block up
+// to and including the block at the passed height.  The result saturates at
+// math.MaxInt64 for schedules which would otherwise overflow it.
+func (p *Params) TotalSupplyAt(height int32) int64 {
+	var total int64
+	eras := p.subsidyEras()
+	for i, era := range eras {
+		if era.Height > height {
+			break
+		}
+
+		// The era ends right before the next one starts or at the
+		// requested height, whichever comes first.
+		last := height
+		if i+1 < len(eras) && eras[i+1].Height-1 < last {
+			last = eras[i+1].Height - 1
+		}
+		blocks := int64(last) - int64(era.Height) + 1
+		if era.Subsidy > 0 && blocks > (math.MaxInt64-total)/era.Subsidy {
+			return math.MaxInt64
+		}
+		total += blocks * era.Subsidy
+	}
+
+	// Superblocks are paid their subsidy instead of the base subsidy.
+	for i := range p.SuperblockRules {
+		rule := &p.SuperblockRules[i]
+		for epoch := rule.FirstEpoch; epoch <= rule.LastEpoch; epoch++ {
+			first := rule.firstSuperblock(epoch)
+			if first > height {
+				break
+			}
+			for h := first; h < first+rule.Length && h <= height; h++ {
+				bonus := rule.Subsidy - p.baseSubsidy(h)
+				if bonus > 0 && total > math.MaxInt64-bonus {
+					return math.MaxInt64
+				}
+				total += bonus
+			}
+		}
+	}
+	return total
+}
+
+// MaxMoney returns the total amount, in the smallest unit of the coin, that
+// block subsidies will ever create on the network.  For schedules which never
+// reach a zero subsidy, this is the amount created by the highest possible
+// block height.  Like TotalSupplyAt, it includes superblocks.
+func (p *Params) MaxMoney() int64 {
+	return p.TotalSupplyAt(math.MaxInt32)
+}
+
+// validateSubsidyEras returns an error when the heights of the subsidy eras of
+// the network are not strictly ascending, which BlockSubsidy relies on, when
+// an era or a superblock rule pays a negative subsidy, or when a superblock
+// rule can not place its superblocks within its epochs.
+func (p *Params) validateSubsidyEras() error {
+	eras := p.SubsidyEras
+	for i, era := range eras {
+		if i > 0 && era.Height <= eras[i-1].Height {
+			return ErrSubsidyErasNotAscending
+		}
+		if era.Subsidy < 0 {
+			return ErrNegativeSubsidy
+		}
+	}
+
+	for _, rule := range p.SuperblockRules {
+		if rule.Length <= 0 || rule.EpochLength <= rule.Length ||
+			rule.FirstEpoch <= 0 || rule.LastEpoch < rule.FirstEpoch ||
+			rule.LastEpoch > math.MaxInt32/rule.EpochLength {
+
+			return ErrInvalidSuperblockRule
+		}
+		if rule.Subsidy < 0 {
+			return ErrNegativeSubsidy
+		}
+	}
+	return nil
+}
+
+// mt19937 is the 32-bit Mersenne Twister pseudorandom number generator as
+// implemented by boost::mt19937, which the reference implementation uses to
+// place superblocks.
+type mt19937 struct {
+	state [624]uint32
+	index int
+}
+
+// newMT19937 returns a Mersenne Twister seeded with the passed value.
+func newMT19937(seed uint32) *mt19937 {
+	gen := &mt19937{index: len(mt19937{}.state)}
+	gen.state[0] = seed
+	for i := 1; i < len(gen.state); i++ {
+		prev := gen.state[i-1]
+		gen.state[i] = 1812433253*(prev^(prev>>30)) + uint32(i)
+	}
+	return gen
+}
+
+// next returns the next 32-bit output of the generator.
+func (gen *mt19937) next() uint32 {
+	const n, m = len(mt19937{}.state), 397
+	if gen.index >= n {
+		for i := 0; i < n; i++ {
+			y := gen.state[i]&0x80000000 | gen.state[(i+1)%n]&0x7fffffff
+			v := gen.state[(i+m)%n] ^ y>>1
+			if y&1 != 0 {
+				v ^= 0x9908b0df
+			}
+			gen.state[i] = v
+		}
+		gen.index = 0
+	}
+
+	y := gen.state[gen.index]
+	gen.index++
+	y ^= y >> 11
+	y ^= y << 7 & 0x9d2c5680
+	y ^= y << 15 & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// uniformInt returns an integer between min and max, both inclusive, drawn
+// from the generator the way boost::uniform_int does, which divides the
+// outputs of the generator into equally sized buckets and rejects the outputs
+// beyond the last full bucket.
+func (gen *mt19937) uniformInt(min, max uint32) uint32 {
+	r := max - min
+	if r == math.MaxUint32 {
+		return gen.next()
+	}
+	bucketSize := math.MaxUint32 / (r + 1)
+	if math.MaxUint32%(r+1) == r {
+		bucketSize++
+	}
+	for {
+		if result := gen.next() / bucketSize; result <= r {
+			return result + min
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"math"
+	"testing"
+)
+
+// TestBlockSubsidy ensures the block subsidy follows the subsidy schedule of
+// the default networks and an explicit era table when one is set.
+func TestBlockSubsidy(t *testing.T) {
+	eraParams := Params{
+		SubsidyReductionInterval: 100,
+		BaseSubsidy:              50 * 1e8,
+		SubsidyEras: []SubsidyEra{
+			{Height: 1, Subsidy: 1024 * 1e8},
+			{Height: 1000, Subsidy: 512 * 1e8},
+			{Height: 5000, Subsidy: 1e8},
+			{Height: 10000, Subsidy: 0},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		params *Params
+		height int32
+		want   int64
+	}{
+		{"mainnet genesis", &MainNetParams, 0, 50 * 1e8},
+		{"mainnet premine", &MainNetParams, 1, 10747 * 1e8},
+		{"mainnet block 2", &MainNetParams, 2, 1024 * 1e8},
+		{"mainnet end of first era", &MainNetParams, 72000, 1024 * 1e8},
+		{"mainnet second era", &MainNetParams, 72001, 512 * 1e8},
+		{"mainnet 64 coins", &MainNetParams, 576000, 64 * 1e8},
+		{"mainnet 32 coins", &MainNetParams, 576001, 32 * 1e8},
+		{"mainnet 2 coins", &MainNetParams, 5256000, 2 * 1e8},
+		{"mainnet last coin", &MainNetParams, 26280000, 1e8},
+		{"mainnet exhausted", &MainNetParams, 26280001, 0},
+		{"mainnet max height", &MainNetParams, math.MaxInt32, 0},
+		{"mainnet negative height", &MainNetParams, -1, 0},
+		{"testnet4 premine", &TestNet4Params, 1, 10747 * 1e8},
+		{"testnet4 second era", &TestNet4Params, 72001, 512 * 1e8},
+		{"regtest first halving", &RegressionNetParams, 150, 25 * 1e8},
+		{"simnet first halving", &SimNetParams, 210000, 25 * 1e8},
+		{"era before first era", &eraParams, 0, 0},
+		{"era first", &eraParams, 1, 1024 * 1e8},
+		{"era end of first", &eraParams, 999, 1024 * 1e8},
+		{"era second", &eraParams, 1000, 512 * 1e8},
+		{"era third", &eraParams, 9999, 1e8},
+		{"era exhausted", &eraParams, 10000, 0},
+	}
+
+	for _, test := range tests {
+		subsidy := test.params.BlockSubsidy(test.height)
+		if subsidy != test.want {
+			t.Errorf("%s: BlockSubsidy(%d) = %d, want %d", test.name,
+				test.height, subsidy, test.want)
+		}
+	}
+}
+
+// TestTotalSupplyAt ensures the total supply matches the sum of the block
+// subsidies up to a height.
+func TestTotalSupplyAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *Params
+		height int32
+		want   int64
+	}{
+		{"negative height", &MainNetParams, -1, 0},
+		{"genesis", &MainNetParams, 0, 50 * 1e8},
+		{"premine", &MainNetParams, 1, 10797 * 1e8},
+		{"block 2", &MainNetParams, 2, 11821 * 1e8},
+		{"before first superblock", &MainNetParams, 36000, 36873773 * 1e8},
+
+		// The 180 superblocks of the second epoch pay 2973 rather than
+		// 1024 coins wherever they fall within the epoch.
+		{"end of first era", &MainNetParams, 72000, 74088593 * 1e8},
+		{"second era", &MainNetParams, 72001, 74089105 * 1e8},
+		{"regtest second era", &RegressionNetParams, 199, 150*50*1e8 + 50*25*1e8},
+	}
+
+	for _, test := range tests {
+		total := test.params.TotalSupplyAt(test.height)
+		if total != test.want {
+			t.Errorf("%s: TotalSupplyAt(%d) = %d, want %d", test.name,
+				test.height, total, test.want)
+		}
+	}
+
+	// Ensure the total supply agrees with summing the subsidies block by
+	// block over several regtest eras.
+	var sum int64
+	for height := int32(0); height < 1000; height++ {
+		sum += RegressionNetParams.BlockSubsidy(height)
+		total := RegressionNetParams.TotalSupplyAt(height)
+		if total != sum {
+			t.Fatalf("TotalSupplyAt(%d) = %d, want %d", height, total,
+				sum)
+		}
+	}
+}
+
+// TestMaxMoney ensures the sum of the subsidies over all eras matches the
+// supply of the schedule of each default network, and saturates instead of
+// overflowing.
+func TestMaxMoney(t *testing.T) {
+	tests := []struct {
+		params *Params
+		want   int64
+	}{
+		// 50 coins of genesis, the premine of 10747 coins, the
+		// 221614976 coins paid from block 2 to block 26280000 and the
+		// 180 superblocks of each of the epochs from 2 to 147, which
+		// pay 78130440 coins instead of 818820 coins of base subsidy.
+		{&MainNetParams, 29893739300000000},
+		{&TestNet4Params, 29893739300000000},
+		{&RegressionNetParams, 1499999998350},
+		{&SimNetParams, 2099999997690000},
+		{
+			params: &Params{
+				SubsidyEras: []SubsidyEra{
+					{Height: 0, Subsidy: 100},
+					{Height: 10, Subsidy: 10},
+					{Height: 20, Subsidy: 0},
+				},
+			},
+			want: 1100,
+		},
+		{
+			// A tail emission never reaches zero, so the cap is
+			// what the highest possible block height creates.
+			params: &Params{
+				SubsidyEras: []SubsidyEra{
+					{Height: 0, Subsidy: 100},
+					{Height: 10, Subsidy: 1},
+				},
+			},
+			want: 1000 + math.MaxInt32 - 9,
+		},
+		{
+			// A tail emission large enough to overflow saturates.
+			params: &Params{
+				SubsidyEras: []SubsidyEra{
+					{Height: 0, Subsidy: 1024 * 1e8},
+				},
+			},
+			want: math.MaxInt64,
+		},
+	}
+
+	for i, test := range tests {
+		maxMoney := test.params.MaxMoney()
+		if maxMoney != test.want {
+			t.Errorf("#%d (%s): MaxMoney = %d, want %d", i,
+				test.params.Name, maxMoney, test.want)
+		}
+	}
+
+	// The schedule must stay below the documented Einsteinium cap of
+	// 299792458 coins.
+	if maxMoney := MainNetParams.MaxMoney(); maxMoney > 29979245800000000 {
+		t.Errorf("mainnet: MaxMoney = %d exceeds the cap of %d",
+			maxMoney, int64(29979245800000000))
+	}
+}
+
+// TestMT19937 ensures the Mersenne Twister used to place superblocks produces
+// the reference outputs of the algorithm and draws uniform integers like
+// boost::uniform_int.
+func TestMT19937(t *testing.T) {
+	// The C++ standard requires the first output of a Mersenne Twister
+	// seeded with 5489 to be 3499211612 and the 10000th to be 4123659995.
+	gen := newMT19937(5489)
+	if got := gen.next(); got != 3499211612 {
+		t.Fatalf("first output = %d, want 3499211612", got)
+	}
+	for i := 2; i < 10000; i++ {
+		gen.next()
+	}
+	if got := gen.next(); got != 4123659995 {
+		t.Fatalf("10000th output = %d, want 4123659995", got)
+	}
+
+	// Drawing from 1 to 35820 uses buckets of 2^32 / 35820 = 119904
+	// outputs, so the first output 3499211612 falls in bucket 29183.
+	gen = newMT19937(5489)
+	if got := gen.uniformInt(1, 35820); got != 29184 {
+		t.Fatalf("uniformInt = %d, want 29184", got)
+	}
+}
+
+// TestSuperblocks ensures every epoch of a superblock rule contains a single
+// run of superblocks of the expected length which are paid the superblock
+// subsidy, and that no other block is.
+func TestSuperblocks(t *testing.T) {
+	rule := &MainNetParams.SuperblockRules[0]
+	for _, epoch := range []int32{1, 2, 147, 148} {
+		start := (epoch-1)*rule.EpochLength + 1
+		end := epoch * rule.EpochLength
+
+		var first, count int32
+		for height := start; height <= end; height++ {
+			subsidy := MainNetParams.BlockSubsidy(height)
+			if !MainNetParams.IsSuperblock(height) {
+				if subsidy == rule.Subsidy {
+					t.Fatalf("epoch %d: block %d is paid the "+
+						"superblock subsidy", epoch, height)
+				}
+				continue
+			}
+
+			if count == 0 {
+				first = height
+			} else if height != first+count {
+				t.Fatalf("epoch %d: superblock %d is not "+
+					"consecutive", epoch, height)
+			}
+			count++
+			if subsidy != 2973*1e8 {
+				t.Fatalf("epoch %d: BlockSubsidy(%d) = %d, want %d",
+					epoch, height, subsidy, int64(2973*1e8))
+			}
+		}
+
+		want := int32(180)
+		if epoch < 2 || epoch > 147 {
+			want = 0
+		}
+		if count != want {
+			t.Errorf("epoch %d: got %d superblocks, want %d", epoch,
+				count, want)
+		}
+	}
+
+	// The genesis block and negative heights are never superblocks.
+	for _, height := range []int32{-1, 0} {
+		if MainNetParams.IsSuperblock(height) {
+			t.Errorf("IsSuperblock(%d) = true", height)
+		}
+	}
+}
+
+// TestValidateSubsidyEras ensures misordered subsidy eras and negative
+// subsidies are rejected.
+func TestValidateSubsidyEras(t *testing.T) {
+	tests := []struct {
+		name string
+		eras []SubsidyEra
+		err  error
+	}{
+		{"none", nil, nil},
+		{"ascending", []SubsidyEra{{1, 1024 * 1e8}, {1000, 0}}, nil},
+		{
+			"descending",
+			[]SubsidyEra{{1000, 1024 * 1e8}, {1, 0}},
+			ErrSubsidyErasNotAscending,
+		},
+		{
+			"duplicate height",
+			[]SubsidyEra{{1, 1024 * 1e8}, {1, 0}},
+			ErrSubsidyErasNotAscending,
+		},
+		{"negative subsidy", []SubsidyEra{{1, -1}}, ErrNegativeSubsidy},
+	}
+
+	for _, test := range tests {
+		params := Params{SubsidyEras: test.eras}
+		if err := params.validateSubsidyEras(); err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	valid := SuperblockRule{
+		EpochLength: 100,
+		FirstEpoch:  1,
+		LastEpoch:   10,
+		Length:      10,
+		SeedFactor:  1,
+		Subsidy:     1e8,
+	}
+	ruleTests := []struct {
+		name   string
+		modify func(*SuperblockRule)
+		err    error
+	}{
+		{"valid", func(*SuperblockRule) {}, nil},
+		{"no length", func(r *SuperblockRule) { r.Length = 0 },
+			ErrInvalidSuperblockRule},
+		{"length of epoch", func(r *SuperblockRule) { r.Length = 100 },
+			ErrInvalidSuperblockRule},
+		{"epoch zero", func(r *SuperblockRule) { r.FirstEpoch = 0 },
+			ErrInvalidSuperblockRule},
+		{"last before first", func(r *SuperblockRule) { r.LastEpoch = 0 },
+			ErrInvalidSuperblockRule},
+		{"heights overflow", func(r *SuperblockRule) {
+			r.LastEpoch = math.MaxInt32 / 50
+		}, ErrInvalidSuperblockRule},
+		{"negative subsidy", func(r *SuperblockRule) { r.Subsidy = -1 },
+			ErrNegativeSubsidy},
+	}
+	for _, test := range ruleTests {
+		rule := valid
+		test.modify(&rule)
+		params := Params{SuperblockRules: []SuperblockRule{rule}}
+		if err := params.validateSubsidyEras(); err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+const (
+	// medianTimeBlocks is the number of previous blocks which should be
+	// used to calculate the median time used to validate block timestamps.
+	medianTimeBlocks = 11
+
+	// vbTopBits defines the bits to set in the version to signal that the
+	// version bits scheme is being used.
+	vbTopBits = 0x20000000
+
+	// vbTopMask is the bitmask to use to determine whether or not the
+	// version bits scheme is in use.
+	vbTopMask = 0xe0000000
+)
+
+var (
+	// ErrUnknownDeployment describes an error where a deployment ID does
+	// not refer to one of the deployments of the network.
+	ErrUnknownDeployment = errors.New("unknown deployment")
+
+	// ErrNoConfirmationWindow describes an error where the threshold state
+	// of a deployment is requested for a network which does not define a
+	// miner confirmation window.
+	ErrNoConfirmationWindow = errors.New("network does not define a miner " +
+		"confirmation window")
+
+	// ErrInvalidDeployment describes an error where a deployment sets
+	// LockInOnTimeout without being height based or times out before it
+	// starts.
+	ErrInvalidDeployment = errors.New("invalid deployment parameters")
+
+	// ErrNegativeHeight describes an error where the median time past is
+	// requested for a height below the genesis block, for which there are
+	// no blocks to take the median time of.
+	ErrNegativeHeight = errors.New("height below the genesis block")
+)
+
+// ThresholdState define the various threshold states used when voting on
+// consensus changes.
+type ThresholdState byte
+
+// These constants are used to identify specific threshold states.
+const (
+	// ThresholdDefined is the first state for each deployment and is the
+	// state for the genesis block has by definition for all deployments.
+	ThresholdDefined ThresholdState = iota
+
+	// ThresholdStarted is the state for a deployment once its start time
+	// has been reached.
+	ThresholdStarted
+
+	// ThresholdLockedIn is the state for a deployment during the retarget
+	// period which is after the ThresholdStarted state period and the
+	// number of blocks that have voted for the deployment equal or exceed
+	// the required number of votes for the deployment.
+	ThresholdLockedIn
+
+	// ThresholdActive is the state for a deployment for all blocks after a
+	// retarget period in which the deployment was in the ThresholdLockedIn
+	// state.
+	ThresholdActive
+
+	// ThresholdFailed is the state for a deployment once its expiration
+	// time has been reached and it did not reach the ThresholdLockedIn
+	// state.
+	ThresholdFailed
+
+	// ThresholdMustSignal is the state for a height based deployment with
+	// LockInOnTimeout set during the last retarget period before its
+	// timeout height when it did not reach the ThresholdLockedIn state
+	// before.  Every block of the period must signal for the deployment
+	// and it moves to the ThresholdLockedIn state afterwards.
+	ThresholdMustSignal
+
+	// numThresholdsStates is the maximum number of threshold states used in
+	// tests.
+	numThresholdsStates
+)
+
+// thresholdStateStrings is a map of ThresholdState values back to their
+// constant names for pretty printing.
+var thresholdStateStrings = map[ThresholdState]string{
+	ThresholdDefined:    "ThresholdDefined",
+	ThresholdStarted:    "ThresholdStarted",
+	ThresholdLockedIn:   "ThresholdLockedIn",
+	ThresholdActive:     "ThresholdActive",
+	ThresholdFailed:     "ThresholdFailed",
+	ThresholdMustSignal: "ThresholdMustSignal",
+}
+
+// String returns the ThresholdState as a human-readable name.
+func (t ThresholdState) String() string {
+	if s := thresholdStateStrings[t]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown ThresholdState (%d)", int(t))
+}
+
+// PastMedianTime returns the median time of the block at the passed height and
+// the blocks before it, up to medianTimeBlocks blocks in total.  This is the
+// time deployment start and expiration times are compared against.
+// ErrNegativeHeight is returned for heights below the genesis block.
+func PastMedianTime(headers HeaderProvider, height int32) (time.Time, error) {
+	if height < 0 {
+		return time.Time{}, ErrNegativeHeight
+	}
+
+	timestamps := make([]int64, 0, medianTimeBlocks)
+	for i := height; i >= 0 && i > height-medianTimeBlocks; i-- {
+		header, err := headers.HeaderByHeight(i)
+		if err != nil {
+			return time.Time{}, err
+		}
+		timestamps = append(timestamps, header.Timestamp.Unix())
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+
+	// The reference implementation takes the upper median when there is
+	// an even number of timestamps, which only happens near the genesis
+	// block.
+	return time.Unix(timestamps[len(timestamps)/2], 0), nil
+}
+
+// WindowStats houses the signalling statistics of a deployment within a single
+// miner confirmation window.
+type WindowStats struct {
+	// StartHeight is the height of the first block of the window.
+	StartHeight int32
+
+	// Window is the number of blocks in the window and Threshold is the
+	// number of them which must signal for the deployment to lock in.
+	Window    uint32
+	Threshold uint32
+
+	// Elapsed is the number of blocks of the window which have been
+	// examined and Count is the number of those which signal for the
+	// deployment.
+	Elapsed uint32
+	Count   uint32
+
+	// Possible reports whether the deployment can still reach the
+	// threshold within the window.
+	Possible bool
+}
+
+// ThresholdStateMachine evaluates the BIP0009 threshold state of a single
+// deployment of a network against a chain of headers, following BIP0008 for
+// height based deployments and honouring the minimum activation height and the
+// counting of votes before the timeout introduced by the speedy trial
+// activation.  The state changes only at the start of each miner confirmation
+// window, so the state of every evaluated window is cached by the hash of the
+// last block before it.  This keeps the cache valid across chain
+// reorganizations.
+//
+// It is safe to use a ThresholdStateMachine concurrently.
+type ThresholdStateMachine struct {
+	params     *Params
+	deployment *ConsensusDeployment
+	headers    HeaderProvider
+
+	cacheMtx sync.Mutex
+	cache    map[chainhash.Hash]ThresholdState
+}
+
+// NewThresholdStateMachine returns a state machine which evaluates the
+// threshold state of the deployment with the passed ID, such as DeploymentCSV,
+// using the headers of the passed provider.  ErrUnknownDeployment is returned
+// for IDs which do not refer to a deployment of the network and
+// ErrNoConfirmationWindow when the network does not define
+// MinerConfirmationWindow.
+func NewThresholdStateMachine(params *Params, deploymentID uint32,
+	headers HeaderProvider) (*ThresholdStateMachine, error) {
+
+	deployment, err := params.DeploymentByID(deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	return newThresholdStateMachine(params, deployment, headers)
+}
+
+// NewThresholdStateMachineByName returns a state machine which evaluates the
+// threshold state of the deployment with the passed name.  It returns the same
+// errors as NewThresholdStateMachine.
+func NewThresholdStateMachineByName(params *Params, name string,
+	headers HeaderProvider) (*ThresholdStateMachine, error) {
+
+	deployment, ok := params.Deployment(name)
+	if !ok {
+		return nil, ErrUnknownDeployment
+	}
+	return newThresholdStateMachine(params, deployment, headers)
+}
+
+// newThresholdStateMachine returns a state machine which evaluates the
+// threshold state of the passed deployment of the network.
+func newThresholdStateMachine(params *Params, deployment *ConsensusDeployment,
+	headers HeaderProvider) (*ThresholdStateMachine, error) {
+
+	if params.MinerConfirmationWindow == 0 {
+		return nil, ErrNoConfirmationWindow
+	}
+
+	return &ThresholdStateMachine{
+		params:     params,
+		deployment: deployment,
+		headers:    headers,
+		cache:      make(map[chainhash.Hash]ThresholdState),
+	}, nil
+}
+
+// signals returns whether the passed block version signals for the
+// deployment.
+func (m *ThresholdStateMachine) signals(version int32) bool {
+	return uint32(version)&vbTopMask == vbTopBits &&
+		uint32(version)&(uint32(1)<<m.deployment.BitNumber) != 0
+}
+
+// countSignals returns the number of blocks from startHeight to endHeight,
+// both inclusive, which signal for the deployment.
+func (m *ThresholdStateMachine) countSignals(startHeight,
+	endHeight int32) (uint32, error) {
+
+	var count uint32
+	for height := startHeight; height <= endHeight; height++ {
+		header, err := m.headers.HeaderByHeight(height)
+		if err != nil {
+			return 0, err
+		}
+		if m.signals(header.Version) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// hasStarted returns whether voting on the deployment may have started in the
+// window which follows the block at prevHeight with the passed past median
+// time.
+func (m *ThresholdStateMachine) hasStarted(prevHeight int32,
+	medianTime uint64) bool {
+
+	if m.deployment.IsHeightBased() {
+		return prevHeight+1 >= m.deployment.StartHeight
+	}
+	return medianTime >= m.deployment.StartTime
+}
+
+// nextState returns the state of the window which follows the window ending
+// with the block at prevHeight, given the state of that window and the past
+// median time of the block.
+func (m *ThresholdStateMachine) nextState(state ThresholdState,
+	prevHeight int32, medianTime uint64) (ThresholdState, error) {
+
+	if m.deployment.IsHeightBased() {
+		return m.nextHeightBasedState(state, prevHeight)
+	}
+
+	deployment := m.deployment
+	switch state {
+	case ThresholdDefined:
+		// The deployment of the rule change fails if it expires
+		// before it is accepted and locked in.
+		if medianTime >= deployment.ExpireTime {
+			return ThresholdFailed, nil
+		}
+
+		// The state for the rule moves to the started state once its
+		// start time has been reached (and it hasn't already expired
+		// per the above).
+		if medianTime >= deployment.StartTime {
+			return ThresholdStarted, nil
+		}
+
+	case ThresholdStarted:
+		// The deployment of the rule change fails if it expires
+		// before it is accepted and locked in.  Deployments which
+		// count the votes before the timeout, such as speedy trial
+		// deployments, count the votes of the window first so a
+		// window reaching the threshold locks in even though it ends
+		// past the expiration time.
+		expired := medianTime >= deployment.ExpireTime
+		if expired && !deployment.CountBeforeTimeout {
+			return ThresholdFailed, nil
+		}
+
+		// The state is locked in if the number of blocks in the
+		// window that voted for the rule change meets the activation
+		// threshold.
+		lockedIn, err := m.reachedThreshold(prevHeight)
+		if err != nil {
+			return state, err
+		}
+		if lockedIn {
+			return ThresholdLockedIn, nil
+		}
+		if expired {
+			return ThresholdFailed, nil
+		}
+
+	case ThresholdLockedIn:
+		// The new rule becomes active when its previous state was
+		// locked in, unless it may not activate yet.
+		if prevHeight+1 >= deployment.MinActivationHeight {
+			return ThresholdActive, nil
+		}
+
+	// Nothing to do if the previous state is active or failed since they
+	// are both terminal states.
+	case ThresholdActive:
+	case ThresholdFailed:
+	}
+
+	return state, nil
+}
+
+// nextHeightBasedState returns the state of the window which follows the
+// window ending with the block at prevHeight for height based deployments.
+// The transitions follow BIP0008, which counts the votes of a window before
+// considering the timeout.
+func (m *ThresholdStateMachine) nextHeightBasedState(state ThresholdState,
+	prevHeight int32) (ThresholdState, error) {
+
+	deployment := m.deployment
+	height := prevHeight + 1
+	switch state {
+	case ThresholdDefined:
+		if height >= deployment.StartHeight {
+			return ThresholdStarted, nil
+		}
+
+	case ThresholdStarted:
+		lockedIn, err := m.reachedThreshold(prevHeight)
+		if err != nil {
+			return state, err
+		}
+		window := int32(m.params.MinerConfirmationWindow)
+		switch {
+		case lockedIn:
+			return ThresholdLockedIn, nil
+
+		// The last window before the timeout must signal when the
+		// deployment locks in on timeout.
+		case deployment.LockInOnTimeout &&
+			height+window >= deployment.TimeoutHeight:
+			return ThresholdMustSignal, nil
+
+		case height >= deployment.TimeoutHeight:
+			return ThresholdFailed, nil
+		}
+
+	case ThresholdMustSignal:
+		return ThresholdLockedIn, nil
+
+	case ThresholdLockedIn:
+		if height >= deployment.MinActivationHeight {
+			return ThresholdActive, nil
+		}
+
+	// Nothing to do if the previous state is active or failed since they
+	// are both terminal states.
+	case ThresholdActive:
+	case ThresholdFailed:
+	}
+
+	return state, nil
+}
+
+// reachedThreshold returns whether the number of blocks in the window ending
+// with the block at prevHeight which voted for the rule change meets the
+// activation threshold.
+func (m *ThresholdStateMachine) reachedThreshold(prevHeight int32) (bool, error) {
+	window := int32(m.params.MinerConfirmationWindow)
+	count, err := m.countSignals(prevHeight-window+1, prevHeight)
+	if err != nil {
+		return false, err
+	}
+	return count >= m.params.RuleChangeActivationThreshold, nil
+}
+
+// State returns the threshold state of the deployment for the block at the
+// passed height.
+func (m *ThresholdStateMachine) State(height int32) (ThresholdState, error) {
+	// The threshold state for the window that contains the genesis block
+	// is defined by definition.
+	window := int32(m.params.MinerConfirmationWindow)
+	if height < window {
+		return ThresholdDefined, nil
+	}
+
+	m.cacheMtx.Lock()
+	defer m.cacheMtx.Unlock()
+
+	// Walk backwards through each of the previous confirmation windows to
+	// find the most recently cached threshold state, collecting the last
+	// block of every window whose successor state is not known yet.
+	type windowEnd struct {
+		height     int32
+		hash       chainhash.Hash
+		medianTime uint64
+	}
+	var neededStates []windowEnd
+	state := ThresholdDefined
+	for prevHeight := height - height%window - 1; prevHeight >= 0; prevHeight -= window {
+		header, err := m.headers.HeaderByHeight(prevHeight)
+		if err != nil {
+			return ThresholdFailed, err
+		}
+		hash := header.BlockHash()
+
+		// Nothing more to do once there is a cached state.
+		if cachedState, ok := m.cache[hash]; ok {
+			state = cachedState
+			break
+		}
+
+		// The start and expiration times are based on the median
+		// block time, so calculate it now.
+		medianTime, err := PastMedianTime(m.headers, prevHeight)
+		if err != nil {
+			return ThresholdFailed, err
+		}
+
+		// The state is simply defined if the start time or height
+		// hasn't been reached yet.
+		unixTime := uint64(0)
+		if medianTime.Unix() > 0 {
+			unixTime = uint64(medianTime.Unix())
+		}
+		if !m.hasStarted(prevHeight, unixTime) {
+			m.cache[hash] = ThresholdDefined
+			break
+		}
+
+		neededStates = append(neededStates, windowEnd{
+			height:     prevHeight,
+			hash:       hash,
+			medianTime: unixTime,
+		})
+	}
+
+	// Since each threshold state depends on the state of the previous
+	// window, iterate starting from the oldest unknown window.
+	for i := len(neededStates) - 1; i >= 0; i-- {
+		end := &neededStates[i]
+		var err error
+		state, err = m.nextState(state, end.height, end.medianTime)
+		if err != nil {
+			return ThresholdFailed, err
+		}
+
+		// Update the cache to avoid recalculating the state in the
+		// future.
+		m.cache[end.hash] = state
+	}
+
+	return state, nil
+}
+
+// WindowStats returns the signalling statistics of the deployment within the
+// miner confirmation window which contains the block at the passed height.
+// Only the blocks of the window up to and including that block are examined.
+func (m *ThresholdStateMachine) WindowStats(height int32) (WindowStats, error) {
+	window := m.params.MinerConfirmationWindow
+	startHeight := height - height%int32(window)
+	count, err := m.countSignals(startHeight, height)
+	if err != nil {
+		return WindowStats{}, err
+	}
+
+	elapsed := uint32(height-startHeight) + 1
+	threshold := m.params.RuleChangeActivationThreshold
+	return WindowStats{
+		StartHeight: startHeight,
+		Window:      window,
+		Threshold:   threshold,
+		Elapsed:     elapsed,
+		Count:       count,
+		Possible:    count+window-elapsed >= threshold,
+	}, nil
+}
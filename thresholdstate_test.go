@@ -0,0 +1,569 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// TestThresholdStateStringer tests the stringized output for the
+// ThresholdState type.
+func TestThresholdStateStringer(t *testing.T) {
+	tests := []struct {
+		in   ThresholdState
+		want string
+	}{
+		{ThresholdDefined, "ThresholdDefined"},
+		{ThresholdStarted, "ThresholdStarted"},
+		{ThresholdLockedIn, "ThresholdLockedIn"},
+		{ThresholdActive, "ThresholdActive"},
+		{ThresholdFailed, "ThresholdFailed"},
+		{ThresholdMustSignal, "ThresholdMustSignal"},
+		{0xff, "Unknown ThresholdState (255)"},
+	}
+
+	// Detect additional threshold states that don't have the stringer
+	// tested.
+	if len(tests)-1 != int(numThresholdsStates) {
+		t.Errorf("It appears a threshold state was added without adding " +
+			"an associated stringer test")
+	}
+
+	for i, test := range tests {
+		result := test.in.String()
+		if result != test.want {
+			t.Errorf("String #%d\n got: %s want: %s", i, result,
+				test.want)
+		}
+	}
+}
+
+// thresholdTestParams returns parameters with a confirmation window of ten
+// blocks and a threshold of eight whose test dummy deployment uses bit zero.
+func thresholdTestParams(startTime, expireTime uint64) *Params {
+	return &Params{
+		TargetTimespan:                time.Hour,
+		TargetTimePerBlock:            time.Minute,
+		RuleChangeActivationThreshold: 8,
+		MinerConfirmationWindow:       10,
+		Deployments: []ConsensusDeployment{{
+			Name:       DeploymentNameTestDummy,
+			BitNumber:  0,
+			StartTime:  startTime,
+			ExpireTime: expireTime,
+		}},
+	}
+}
+
+// thresholdTestChain returns a chain of the passed number of headers mined ten
+// minutes apart whose versions are returned by the passed function.
+func thresholdTestChain(numBlocks int, version func(height int) int32) mockHeaders {
+	headers := make(mockHeaders, numBlocks)
+	for i := range headers {
+		headers[i] = wire.BlockHeader{
+			Version:   version(i),
+			Timestamp: thresholdTestTime(i),
+			Nonce:     uint32(i),
+		}
+	}
+	return headers
+}
+
+// thresholdTestTime returns the timestamp of the block at the passed height of
+// the threshold test chains.
+func thresholdTestTime(height int) time.Time {
+	return time.Unix(1500000000+int64(height)*600, 0)
+}
+
+// TestPastMedianTime ensures the median time past matches the reference
+// implementation, including near the genesis block.
+func TestPastMedianTime(t *testing.T) {
+	headers := thresholdTestChain(30, func(int) int32 { return 1 })
+
+	// Make a timestamp out of order to ensure they are sorted.
+	headers[18].Timestamp = thresholdTestTime(25)
+
+	tests := []struct {
+		height int32
+		want   int
+	}{
+		{0, 0},
+		{1, 1},
+		{9, 5},
+		{10, 5},
+		{15, 10},
+		{20, 15},
+		{25, 21},
+	}
+
+	for _, test := range tests {
+		medianTime, err := PastMedianTime(headers, test.height)
+		if err != nil {
+			t.Errorf("PastMedianTime(%d) unexpected error: %v",
+				test.height, err)
+			continue
+		}
+		if want := thresholdTestTime(test.want); !medianTime.Equal(want) {
+			t.Errorf("PastMedianTime(%d) = %v, want %v", test.height,
+				medianTime, want)
+		}
+	}
+	// Heights below the genesis block have no median time.
+	for _, height := range []int32{-1, math.MinInt32} {
+		_, err := PastMedianTime(headers, height)
+		if err != ErrNegativeHeight {
+			t.Errorf("PastMedianTime(%d) = %v, want %v", height, err,
+				ErrNegativeHeight)
+		}
+	}
+}
+
+// TestThresholdState ensures the threshold state machine follows the BIP0009
+// state transitions, and that deployments which count the votes before the
+// timeout, such as speedy trial deployments, count the votes of a window before
+// expiring.
+func TestThresholdState(t *testing.T) {
+	// The median time past of the last block of a window is the timestamp
+	// of the block five blocks before it, so the deployment starts in the
+	// window beginning at height 20.
+	startTime := uint64(thresholdTestTime(14).Unix())
+	expireTime := uint64(thresholdTestTime(24).Unix())
+
+	// signalling returns a version function which signals for bit zero in
+	// the number of blocks starting from height 20.
+	signalling := func(numSignals int) func(int) int32 {
+		return func(height int) int32 {
+			if height >= 20 && height < 20+numSignals {
+				return vbTopBits | 1
+			}
+			return vbTopBits
+		}
+	}
+
+	tests := []struct {
+		name                string
+		expireTime          uint64
+		minActivationHeight int32
+		countBeforeTimeout  bool
+		version             func(int) int32
+		states              map[int32]ThresholdState
+	}{
+		{
+			name:       "locked in and activated",
+			expireTime: expireTime + 1e6,
+			version:    signalling(8),
+			states: map[int32]ThresholdState{
+				0:  ThresholdDefined,
+				19: ThresholdDefined,
+				20: ThresholdStarted,
+				29: ThresholdStarted,
+				30: ThresholdLockedIn,
+				39: ThresholdLockedIn,
+				40: ThresholdActive,
+				59: ThresholdActive,
+			},
+		},
+		{
+			name:       "below threshold",
+			expireTime: expireTime + 1e6,
+			version:    signalling(7),
+			states: map[int32]ThresholdState{
+				20: ThresholdStarted,
+				30: ThresholdStarted,
+				59: ThresholdStarted,
+			},
+		},
+		{
+			name:       "expired",
+			expireTime: expireTime,
+			version:    signalling(7),
+			states: map[int32]ThresholdState{
+				20: ThresholdStarted,
+				29: ThresholdStarted,
+				30: ThresholdFailed,
+				59: ThresholdFailed,
+			},
+		},
+		{
+			// Speedy trial counts the votes of the window ending
+			// past the expiration time before expiring.
+			name:                "speedy trial locked in at expiry",
+			expireTime:          expireTime,
+			minActivationHeight: 50,
+			countBeforeTimeout:  true,
+			version:             signalling(8),
+			states: map[int32]ThresholdState{
+				20: ThresholdStarted,
+				29: ThresholdStarted,
+				30: ThresholdLockedIn,
+				49: ThresholdLockedIn,
+				50: ThresholdActive,
+				59: ThresholdActive,
+			},
+		},
+		{
+			name:                "speedy trial expired",
+			expireTime:          expireTime,
+			minActivationHeight: 50,
+			countBeforeTimeout:  true,
+			version:             signalling(7),
+			states: map[int32]ThresholdState{
+				29: ThresholdStarted,
+				30: ThresholdFailed,
+				59: ThresholdFailed,
+			},
+		},
+		{
+			// Counting the votes before the timeout does not
+			// require a minimum activation height.
+			name:               "counted before timeout without minimum activation height",
+			expireTime:         expireTime,
+			countBeforeTimeout: true,
+			version:            signalling(8),
+			states: map[int32]ThresholdState{
+				29: ThresholdStarted,
+				30: ThresholdLockedIn,
+				39: ThresholdLockedIn,
+				40: ThresholdActive,
+				59: ThresholdActive,
+			},
+		},
+		{
+			// A minimum activation height alone keeps the BIP0009
+			// expiration, which fails before counting the votes.
+			name:                "minimum activation height without counting before timeout",
+			expireTime:          expireTime,
+			minActivationHeight: 50,
+			version:             signalling(8),
+			states: map[int32]ThresholdState{
+				29: ThresholdStarted,
+				30: ThresholdFailed,
+				59: ThresholdFailed,
+			},
+		},
+		{
+			name:       "expiry before start",
+			expireTime: startTime,
+			version:    signalling(10),
+			states: map[int32]ThresholdState{
+				19: ThresholdDefined,
+				20: ThresholdFailed,
+				59: ThresholdFailed,
+			},
+		},
+		{
+			name:       "wrong top bits",
+			expireTime: expireTime + 1e6,
+			version: func(height int) int32 {
+				return 0x40000001
+			},
+			states: map[int32]ThresholdState{
+				30: ThresholdStarted,
+				59: ThresholdStarted,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		params := thresholdTestParams(startTime, test.expireTime)
+		deployment := &params.Deployments[0]
+		deployment.MinActivationHeight = test.minActivationHeight
+		deployment.CountBeforeTimeout = test.countBeforeTimeout
+		headers := thresholdTestChain(60, test.version)
+		m, err := NewThresholdStateMachine(params, DeploymentTestDummy,
+			headers)
+		if err != nil {
+			t.Fatalf("%s: NewThresholdStateMachine unexpected "+
+				"error: %v", test.name, err)
+		}
+
+		// Query the states twice to exercise the cache.
+		for i := 0; i < 2; i++ {
+			for height, want := range test.states {
+				state, err := m.State(height)
+				if err != nil {
+					t.Errorf("%s: State(%d) unexpected "+
+						"error: %v", test.name, height, err)
+					continue
+				}
+				if state != want {
+					t.Errorf("%s: State(%d) = %v, want %v",
+						test.name, height, state, want)
+				}
+			}
+		}
+	}
+}
+
+// TestWindowStats ensures the signalling statistics cover the blocks of the
+// window up to the requested height.
+func TestWindowStats(t *testing.T) {
+	params := thresholdTestParams(0, 1<<62)
+	headers := thresholdTestChain(30, func(height int) int32 {
+		if height >= 20 && height < 25 {
+			return vbTopBits | 1
+		}
+		return vbTopBits | 2
+	})
+	m, err := NewThresholdStateMachine(params, DeploymentTestDummy, headers)
+	if err != nil {
+		t.Fatalf("NewThresholdStateMachine unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		height int32
+		want   WindowStats
+	}{
+		{9, WindowStats{StartHeight: 0, Window: 10, Threshold: 8,
+			Elapsed: 10, Count: 0, Possible: false}},
+		{22, WindowStats{StartHeight: 20, Window: 10, Threshold: 8,
+			Elapsed: 3, Count: 3, Possible: true}},
+		{26, WindowStats{StartHeight: 20, Window: 10, Threshold: 8,
+			Elapsed: 7, Count: 5, Possible: true}},
+		{27, WindowStats{StartHeight: 20, Window: 10, Threshold: 8,
+			Elapsed: 8, Count: 5, Possible: false}},
+	}
+
+	for _, test := range tests {
+		stats, err := m.WindowStats(test.height)
+		if err != nil {
+			t.Errorf("WindowStats(%d) unexpected error: %v",
+				test.height, err)
+			continue
+		}
+		if stats != test.want {
+			t.Errorf("WindowStats(%d) = %+v, want %+v", test.height,
+				stats, test.want)
+		}
+	}
+
+	_, err = NewThresholdStateMachine(params, DefinedDeployments, headers)
+	if err != ErrUnknownDeployment {
+		t.Errorf("NewThresholdStateMachine error = %v, want %v", err,
+			ErrUnknownDeployment)
+	}
+	_, err = NewThresholdStateMachine(params, DeploymentCSV, headers)
+	if err != ErrUnknownDeployment {
+		t.Errorf("NewThresholdStateMachine error = %v, want %v", err,
+			ErrUnknownDeployment)
+	}
+	_, err = NewThresholdStateMachineByName(&MainNetParams,
+		DeploymentNameCSV, headers)
+	if err != nil {
+		t.Errorf("NewThresholdStateMachineByName unexpected error: %v",
+			err)
+	}
+	noWindow := *params
+	noWindow.MinerConfirmationWindow = 0
+	_, err = NewThresholdStateMachine(&noWindow, DeploymentTestDummy,
+		headers)
+	if err != ErrNoConfirmationWindow {
+		t.Errorf("NewThresholdStateMachine error = %v, want %v", err,
+			ErrNoConfirmationWindow)
+	}
+}
+
+// TestHeightBasedThresholdState ensures height based deployments follow the
+// BIP0008 state transitions and locked in deployments honour the minimum
+// activation height.
+func TestHeightBasedThresholdState(t *testing.T) {
+	// signalling returns a version function which signals for bit zero in
+	// the blocks from startHeight to endHeight, both exclusive.
+	signalling := func(startHeight, endHeight int) func(int) int32 {
+		return func(height int) int32 {
+			if height >= startHeight && height < endHeight {
+				return vbTopBits | 1
+			}
+			return vbTopBits
+		}
+	}
+
+	tests := []struct {
+		name       string
+		deployment ConsensusDeployment
+		version    func(int) int32
+		states     map[int32]ThresholdState
+	}{
+		{
+			name: "locked in before timeout",
+			deployment: ConsensusDeployment{
+				StartHeight:   20,
+				TimeoutHeight: 50,
+			},
+			version: signalling(30, 40),
+			states: map[int32]ThresholdState{
+				19: ThresholdDefined,
+				20: ThresholdStarted,
+				39: ThresholdStarted,
+				40: ThresholdLockedIn,
+				50: ThresholdActive,
+			},
+		},
+		{
+			name: "locked in during the last window",
+			deployment: ConsensusDeployment{
+				StartHeight:   20,
+				TimeoutHeight: 40,
+			},
+			version: signalling(30, 40),
+			states: map[int32]ThresholdState{
+				30: ThresholdStarted,
+				40: ThresholdLockedIn,
+				50: ThresholdActive,
+			},
+		},
+		{
+			name: "timed out",
+			deployment: ConsensusDeployment{
+				StartHeight:   20,
+				TimeoutHeight: 40,
+			},
+			version: signalling(0, 0),
+			states: map[int32]ThresholdState{
+				39: ThresholdStarted,
+				40: ThresholdFailed,
+				59: ThresholdFailed,
+			},
+		},
+		{
+			name: "lock in on timeout",
+			deployment: ConsensusDeployment{
+				StartHeight:     20,
+				TimeoutHeight:   40,
+				LockInOnTimeout: true,
+			},
+			version: signalling(0, 0),
+			states: map[int32]ThresholdState{
+				20: ThresholdStarted,
+				30: ThresholdMustSignal,
+				40: ThresholdLockedIn,
+				50: ThresholdActive,
+			},
+		},
+		{
+			name: "minimum activation height",
+			deployment: ConsensusDeployment{
+				StartHeight:         20,
+				TimeoutHeight:       50,
+				MinActivationHeight: 55,
+			},
+			version: signalling(20, 30),
+			states: map[int32]ThresholdState{
+				30: ThresholdLockedIn,
+				59: ThresholdLockedIn,
+				60: ThresholdActive,
+			},
+		},
+		{
+			name: "speedy trial",
+			deployment: ConsensusDeployment{
+				StartTime:           uint64(thresholdTestTime(14).Unix()),
+				ExpireTime:          uint64(thresholdTestTime(34).Unix()),
+				MinActivationHeight: 50,
+				CountBeforeTimeout:  true,
+			},
+			version: signalling(20, 30),
+			states: map[int32]ThresholdState{
+				20: ThresholdStarted,
+				30: ThresholdLockedIn,
+				49: ThresholdLockedIn,
+				50: ThresholdActive,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		params := thresholdTestParams(0, 0)
+		params.Deployments[0] = test.deployment
+		params.Deployments[0].Name = DeploymentNameTestDummy
+		if err := params.Validate(); err != nil {
+			t.Errorf("%s: Validate unexpected error: %v", test.name,
+				err)
+			continue
+		}
+
+		headers := thresholdTestChain(70, test.version)
+		m, err := NewThresholdStateMachine(params, DeploymentTestDummy,
+			headers)
+		if err != nil {
+			t.Fatalf("%s: NewThresholdStateMachine unexpected "+
+				"error: %v", test.name, err)
+		}
+		for height, want := range test.states {
+			state, err := m.State(height)
+			if err != nil {
+				t.Errorf("%s: State(%d) unexpected error: %v",
+					test.name, height, err)
+				continue
+			}
+			if state != want {
+				t.Errorf("%s: State(%d) = %v, want %v", test.name,
+					height, state, want)
+			}
+		}
+	}
+}
+
+// TestValidateDeployments ensures inconsistent BIP0008 parameters are rejected.
+func TestValidateDeployments(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment ConsensusDeployment
+		err        error
+	}{
+		{
+			name: "time based",
+			deployment: ConsensusDeployment{
+				StartTime:  1,
+				ExpireTime: 2,
+			},
+		},
+		{
+			name: "lock in on timeout without timeout height",
+			deployment: ConsensusDeployment{
+				LockInOnTimeout: true,
+			},
+			err: ErrInvalidDeployment,
+		},
+		{
+			name: "start height without timeout height",
+			deployment: ConsensusDeployment{
+				StartHeight: 20,
+			},
+			err: ErrInvalidDeployment,
+		},
+		{
+			name: "speedy trial",
+			deployment: ConsensusDeployment{
+				StartTime:           1,
+				ExpireTime:          2,
+				MinActivationHeight: 100,
+				CountBeforeTimeout:  true,
+			},
+		},
+		{
+			name: "timeout before start",
+			deployment: ConsensusDeployment{
+				StartHeight:   40,
+				TimeoutHeight: 20,
+			},
+			err: ErrInvalidDeployment,
+		},
+	}
+
+	for _, test := range tests {
+		params := thresholdTestParams(0, 0)
+		params.Deployments[0] = test.deployment
+		params.Deployments[0].Name = DeploymentNameTestDummy
+		if err := params.Validate(); err != test.err {
+			t.Errorf("%s: Validate = %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+}
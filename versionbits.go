@@ -0,0 +1,78 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+// vbNumBits is the total number of bits available for use with the version
+// bits scheme.
+const vbNumBits = 29
+
+// ComputeBlockVersion returns the version a new block should have given the
+// threshold states of the deployments of the network, keyed by deployment
+// name.  The version has the BIP0009 top bits set along with the bit of every
+// deployment which is started, locked in or must signal.  Deployments without
+// a state are treated as defined.  ErrUnknownDeployment is returned when a
+// state refers to a deployment the network does not define.
+func (p *Params) ComputeBlockVersion(states map[string]ThresholdState) (int32, error) {
+	// Set the appropriate bits for each actively defined rule deployment
+	// that is either in the process of being voted on, or locked in for the
+	// activation at the next threshold window change.
+	expectedVersion := uint32(vbTopBits)
+	for name, state := range states {
+		deployment, ok := p.Deployment(name)
+		if !ok {
+			return 0, ErrUnknownDeployment
+		}
+
+		switch state {
+		case ThresholdStarted, ThresholdLockedIn, ThresholdMustSignal:
+			expectedVersion |= uint32(1) << deployment.BitNumber
+		}
+	}
+	return int32(expectedVersion), nil
+}
+
+// VersionSignals describes the deployments a block version signals for.
+type VersionSignals struct {
+	// Deployments holds the names of the deployments of the network whose
+	// bit is set, ordered by bit number.
+	Deployments []string
+
+	// UnknownBits holds the set bits which do not belong to any deployment
+	// of the network, in ascending order.  Blocks setting them indicate
+	// rule changes this software is not aware of.
+	UnknownBits []uint8
+}
+
+// DecodeVersionSignals returns the deployments the passed block version
+// signals for.  Versions which do not have the BIP0009 top bits set do not
+// signal for anything.
+func (p *Params) DecodeVersionSignals(version int32) VersionSignals {
+	var signals VersionSignals
+	if uint32(version)&vbTopMask != vbTopBits {
+		return signals
+	}
+
+	for bit := uint8(0); bit < vbNumBits; bit++ {
+		if uint32(version)&(uint32(1)<<bit) == 0 {
+			continue
+		}
+
+		known := false
+		p.ForEachDeployment(func(deployment *ConsensusDeployment) bool {
+			if deployment.BitNumber != bit {
+				return true
+			}
+			signals.Deployments = append(signals.Deployments,
+				deployment.Name)
+			known = true
+			return true
+		})
+		if !known {
+			signals.UnknownBits = append(signals.UnknownBits, bit)
+		}
+	}
+
+	return signals
+}
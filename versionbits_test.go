@@ -0,0 +1,136 @@
+// Copyright (c) 2013-2022 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestComputeBlockVersion ensures the block version signals for the
+// deployments which are being voted on.
+func TestComputeBlockVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		states map[string]ThresholdState
+		want   int32
+		err    error
+	}{
+		{
+			name: "no deployments",
+			want: 0x20000000,
+		},
+		{
+			name: "started and locked in",
+			states: map[string]ThresholdState{
+				DeploymentNameCSV:     ThresholdStarted,
+				DeploymentNameSegwit:  ThresholdLockedIn,
+				DeploymentNameTaproot: ThresholdMustSignal,
+			},
+			want: 0x20000007,
+		},
+		{
+			name: "defined, active and failed",
+			states: map[string]ThresholdState{
+				DeploymentNameTestDummy: ThresholdDefined,
+				DeploymentNameCSV:       ThresholdActive,
+				DeploymentNameSegwit:    ThresholdFailed,
+			},
+			want: 0x20000000,
+		},
+		{
+			name: "test dummy started",
+			states: map[string]ThresholdState{
+				DeploymentNameTestDummy: ThresholdStarted,
+			},
+			want: 0x30000000,
+		},
+		{
+			name: "unknown deployment",
+			states: map[string]ThresholdState{
+				"unknown": ThresholdStarted,
+			},
+			err: ErrUnknownDeployment,
+		},
+	}
+
+	for _, test := range tests {
+		version, err := MainNetParams.ComputeBlockVersion(test.states)
+		if err != test.err {
+			t.Errorf("%s: ComputeBlockVersion error = %v, want %v",
+				test.name, err, test.err)
+			continue
+		}
+		if version != test.want {
+			t.Errorf("%s: ComputeBlockVersion = %#x, want %#x",
+				test.name, version, test.want)
+		}
+	}
+}
+
+// TestDecodeVersionSignals ensures block versions are decoded into the
+// deployments they signal for.
+func TestDecodeVersionSignals(t *testing.T) {
+	tests := []struct {
+		version int32
+		want    VersionSignals
+	}{
+		{
+			version: 0x20000000,
+			want:    VersionSignals{},
+		},
+		{
+			version: 0x20000003,
+			want: VersionSignals{
+				Deployments: []string{DeploymentNameCSV,
+					DeploymentNameSegwit},
+			},
+		},
+		{
+			version: 0x30000014,
+			want: VersionSignals{
+				Deployments: []string{DeploymentNameTaproot,
+					DeploymentNameTestDummy},
+				UnknownBits: []uint8{4},
+			},
+		},
+		{
+			// Versions without the top bits do not signal.
+			version: 0x00000003,
+			want:    VersionSignals{},
+		},
+		{
+			version: 0x60000003,
+			want:    VersionSignals{},
+		},
+	}
+
+	for _, test := range tests {
+		signals := MainNetParams.DecodeVersionSignals(test.version)
+		if !reflect.DeepEqual(signals, test.want) {
+			t.Errorf("DecodeVersionSignals(%#x) = %+v, want %+v",
+				test.version, signals, test.want)
+		}
+	}
+
+	// The computed version must decode to the deployments it was computed
+	// from.
+	states := map[string]ThresholdState{
+		DeploymentNameSegwit:  ThresholdStarted,
+		DeploymentNameTaproot: ThresholdLockedIn,
+	}
+	version, err := MainNetParams.ComputeBlockVersion(states)
+	if err != nil {
+		t.Fatalf("ComputeBlockVersion unexpected error: %v", err)
+	}
+	signals := MainNetParams.DecodeVersionSignals(version)
+	want := []string{DeploymentNameSegwit, DeploymentNameTaproot}
+	if !reflect.DeepEqual(signals.Deployments, want) ||
+		len(signals.UnknownBits) != 0 {
+
+		t.Errorf("DecodeVersionSignals(%#x) = %+v, want %v", version,
+			signals, want)
+	}
+}